@@ -1,36 +1,159 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/sunr3d/quorum-grep/internal/client"
 	"github.com/sunr3d/quorum-grep/internal/config"
+	"github.com/sunr3d/quorum-grep/internal/discovery"
+	"github.com/sunr3d/quorum-grep/internal/localcluster"
+	"github.com/sunr3d/quorum-grep/internal/tracing"
 	"github.com/sunr3d/quorum-grep/models"
 )
 
 func main() {
+	// подкоманда только первым аргументом; поиск самого слова servers -
+	// после --: mygrep -- servers файл
+	if len(os.Args) > 1 && os.Args[1] == "servers" {
+		os.Exit(serversCommand(os.Args[2:]))
+	}
+
 	flags, err := parseFlags()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "parseFlags: %v\n", err)
 		fmt.Fprintln(os.Stderr, "Использование утилиты: grep [флаги] шаблон [файлы...]")
+		fmt.Fprintln(os.Stderr, "Состояние серверов: grep servers [--drain host:port [--wait]]")
 		os.Exit(1)
 	}
 
+	if err := client.ValidateOptions(flags.Options); err != nil {
+		fmt.Fprintf(os.Stderr, "mygrep: %v\n", err)
+		os.Exit(2)
+	}
+	if flags.Format == client.FormatJSON && flags.Options.Count {
+		fmt.Fprintln(os.Stderr, "mygrep: --json нельзя использовать вместе с -c")
+		os.Exit(2)
+	}
+	output, err := client.NewFormatter(flags.Format, os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mygrep: --format: %v\n", err)
+		os.Exit(2)
+	}
+
 	cfg, err := config.GetConfig()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "config.GetConfig: %v\n", err)
 		os.Exit(1)
 	}
+	if flags.Quorum != "" {
+		if err := client.ApplyQuorumFlag(&cfg.Client.Quorum, flags.Quorum); err != nil {
+			fmt.Fprintf(os.Stderr, "mygrep: --quorum: %v\n", err)
+			os.Exit(2)
+		}
+	}
+	if flags.LocalFallback {
+		cfg.Client.Fallback.Enabled = true
+	}
+
+	var cluster *localcluster.Cluster
+	if flags.LocalCluster > 0 {
+		cluster, err = localcluster.Start(flags.LocalCluster, nil)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "localcluster.Start: %v\n", err)
+			os.Exit(2)
+		}
+		// серверы кластера без TLS и доступны только в этом процессе
+		cfg.Client.ServerList = cluster.Addrs()
+		cfg.Client.Discovery = config.DiscoveryConfig{Mode: discovery.ModeStatic}
+		cfg.Client.TLS = config.ClientTLSConfig{}
+	}
+
+	// Ctrl-C / SIGTERM отменяет все запросы к серверам;
+	// повторный сигнал завершает процесс сразу.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
 
-	client := client.New(cfg)
+	shutdownTracing, err := tracing.Setup(cfg.Client.Tracing, "mygrep")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tracing.Setup: %v\n", err)
+		os.Exit(2)
+	}
 
+	cli, err := client.New(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "client.New: %v\n", err)
+		os.Exit(2)
+	}
+
+	cli.SetOutput(output)
+
+	failed := false
+	var patternErr string
 	for _, file := range flags.Files {
-		if err := client.ProcessFile(file, flags.Options); err != nil {
+		if ctx.Err() != nil {
+			break
+		}
+
+		if err := cli.ProcessFile(ctx, file, flags.Options); err != nil {
+			failed = true
+
+			// ошибка шаблона повторится для каждого файла, выводим ее один раз;
+			// остальные неустранимые ошибки (например, превышение лимита) относятся
+			// к конкретному файлу, и поиск продолжается
+			if errors.Is(err, models.ErrInvalidPattern) {
+				if err.Error() == patternErr {
+					continue
+				}
+				patternErr = err.Error()
+			}
 			fmt.Fprintf(os.Stderr, "client.ProcessFile: %v\n", err)
 		}
 	}
+
+	if err := cli.Finish(); err != nil {
+		fmt.Fprintf(os.Stderr, "client.Finish: %v\n", err)
+		failed = true
+	}
+
+	if flags.Stats {
+		if err := cli.Stats().WriteReport(os.Stderr); err != nil {
+			fmt.Fprintf(os.Stderr, "WriteReport: %v\n", err)
+		}
+	}
+
+	if err := cli.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "client.Close: %v\n", err)
+	}
+	if cluster != nil {
+		if err := cluster.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "cluster.Close: %v\n", err)
+		}
+	}
+
+	// os.Exit не выполняет defer: спаны дописываются явно
+	if err := shutdownTracing(context.Background()); err != nil {
+		fmt.Fprintf(os.Stderr, "tracing: %v\n", err)
+	}
+
+	if ctx.Err() != nil {
+		stop()
+		os.Exit(130)
+	}
+
+	if failed {
+		stop()
+		os.Exit(2)
+	}
 }
 
 // parseFlags - парсит флаги командной строки.
@@ -45,6 +168,13 @@ func parseFlags() (*models.GrepConfig, error) {
 	flag.BoolVar(&opts.Invert, "v", false, "вывести строки, не содержащие шаблон")
 	flag.BoolVar(&opts.Fixed, "F", false, "воспринимать шаблон как фиксированную строку")
 	flag.BoolVar(&opts.LineNum, "n", false, "вывести номер строки перед каждой найденной строкой")
+	quorum := flag.String("quorum", "", "кворум вместо QUORUM из конфига: majority, all, weighted или число чанков")
+	localCluster := flag.Int("local-cluster", 0, "запустить N серверов в процессе вместо SERVER_LIST")
+	localFallback := flag.Bool("local-fallback", false, "обработать локально чанки, которые не обработали серверы")
+	stats := flag.Bool("stats", false, "вывести статистику поиска в stderr")
+	jsonOutput := flag.Bool("json", false, "вывод в NDJSON, совместимом с ripgrep --json (то же, что --format json)")
+	format := flag.String("format", client.FormatText,
+		"формат вывода: text, json, csv, sarif или шаблон, например '{{.File}}:{{.Line}}: {{.Text}}'")
 
 	flag.Parse()
 
@@ -61,8 +191,24 @@ func parseFlags() (*models.GrepConfig, error) {
 		files = []string{"-"}
 	}
 
+	if *localCluster < 0 {
+		return nil, fmt.Errorf("--local-cluster должен быть не меньше 0, указано %d", *localCluster)
+	}
+
+	if *jsonOutput {
+		if *format != client.FormatText && *format != client.FormatJSON {
+			return nil, fmt.Errorf("--json нельзя использовать вместе с --format %q", *format)
+		}
+		*format = client.FormatJSON
+	}
+
 	return &models.GrepConfig{
-		Options: opts,
-		Files:   files,
+		Options:       opts,
+		Files:         files,
+		Stats:         *stats,
+		Quorum:        *quorum,
+		LocalFallback: *localFallback,
+		LocalCluster:  *localCluster,
+		Format:        *format,
 	}, nil
 }
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/sunr3d/quorum-grep/internal/client"
+	"github.com/sunr3d/quorum-grep/internal/config"
+)
+
+// serversCommand - подкоманда mygrep servers: таблица состояния серверов
+// из конфига, с --drain вывод сервера из работы.
+// Возвращает код завершения: 2, если какой-то сервер не ответил.
+func serversCommand(args []string) int {
+	fs := flag.NewFlagSet("mygrep servers", flag.ContinueOnError)
+	drain := fs.String("drain", "", "вывести сервер host:port из работы: новые чанки отклоняются, начатые дорабатываются")
+	wait := fs.Bool("wait", false, "с --drain дождаться завершения начатых чанков")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "mygrep servers: лишние аргументы: %v\n", fs.Args())
+		return 2
+	}
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "config.GetConfig: %v\n", err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cli, err := client.New(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "client.New: %v\n", err)
+		return 2
+	}
+	defer cli.Close()
+
+	if *drain != "" {
+		inFlight, err := cli.Drain(ctx, *drain, *wait)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "client.Drain %s: %v\n", *drain, err)
+			return 2
+		}
+		fmt.Fprintf(os.Stderr, "%s выводится из работы, чанков в обработке: %d\n", *drain, inFlight)
+	}
+
+	statuses := cli.Servers(ctx)
+	if err := client.WriteServersTable(os.Stdout, statuses); err != nil {
+		fmt.Fprintf(os.Stderr, "WriteServersTable: %v\n", err)
+		return 2
+	}
+
+	for _, st := range statuses {
+		if st.Err != nil {
+			return 2
+		}
+	}
+
+	return 0
+}
@@ -5,23 +5,63 @@ import (
 	"flag"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
 	"github.com/wb-go/wbf/zlog"
 
 	"github.com/sunr3d/quorum-grep/internal/config"
 	"github.com/sunr3d/quorum-grep/internal/entrypoint"
+	"github.com/sunr3d/quorum-grep/internal/server"
 )
 
 func main() {
 	zlog.Init()
 	zlog.Logger.Info().Msg("Запуск сервера grep...")
 
-	port := flag.Int("port", 50051, "порт для запуска сервера")
+	cfg := &config.GRPCServerConfig{}
+
+	flag.IntVar(&cfg.Port, "port", 50051, "порт для запуска сервера")
+	flag.IntVar(&cfg.MaxMessageSize, "max-message-size", server.DefaultMaxMessageSize,
+		"максимальный размер входящего сообщения в байтах")
+	flag.IntVar(&cfg.MaxConcurrentChunks, "max-concurrent-chunks", server.DefaultMaxConcurrentChunks,
+		"сколько чанков обрабатывается одновременно")
+	flag.StringVar(&cfg.MaxChunkDuration, "max-chunk-duration", server.DefaultMaxChunkDuration.String(),
+		"бюджет времени на обработку одного чанка")
+	flag.IntVar(&cfg.MaxResponseSize, "max-response-size", server.DefaultMaxResponseSize,
+		"максимальный размер ответа в байтах")
+	flag.IntVar(&cfg.MaxQueuedPerClient, "max-queued-per-client", server.DefaultMaxQueuedPerClient,
+		"сколько чанков клиента может ждать свободного слота")
+	flag.Float64Var(&cfg.RateLimit, "rate-limit", 0, "лимит чанков в секунду на клиента, 0 - без лимита")
+	flag.IntVar(&cfg.RateBurst, "rate-burst", 0, "сколько чанков клиент может отправить разом сверх лимита")
+	flag.IntVar(&cfg.MetricsPort, "metrics-port", 0, "порт HTTP сервера с /metrics, 0 - выключен")
+	flag.StringVar(&cfg.TLS.CertFile, "tls-cert", "", "сертификат сервера (PEM), включает TLS")
+	flag.StringVar(&cfg.TLS.KeyFile, "tls-key", "", "ключ сертификата сервера (PEM)")
+	flag.StringVar(&cfg.TLS.ClientCAFile, "tls-client-ca", "", "CA сертификатов клиентов (PEM), включает mTLS")
+	allowedSubjects := flag.String("tls-allowed-subjects", "",
+		"разрешенные CN/DNS SAN клиентов через запятую")
+	flag.StringVar(&cfg.Auth.TokenFile, "auth-token-file", "", "YAML файл со статическими токенами")
+	flag.StringVar(&cfg.Auth.JWTKeyFile, "auth-jwt-key-file", "", "ключ для проверки JWT (HS256)")
+	flag.BoolVar(&cfg.Admin.Enabled, "admin", false,
+		"AdminService без аутентификации (Drain, неисправности), только для доверенной сети")
+	adminSubjects := flag.String("admin-subjects", "",
+		"CN/DNS SAN клиентов mTLS с доступом к AdminService через запятую")
+	flag.BoolVar(&cfg.Faults.Enabled, "faults", false,
+		"вносить неисправности, переключаются через AdminService (только для проверок)")
+	flag.StringVar(&cfg.Faults.File, "faults-file", "", "YAML файл с неисправностями по методам, включает --faults")
+	flag.StringVar(&cfg.Tracing.Exporter, "trace-exporter", "", "экспорт спанов: stdout, stderr, file; пусто - выключен")
+	flag.StringVar(&cfg.Tracing.File, "trace-file", "", "файл для экспортера file")
+	flag.Float64Var(&cfg.Tracing.SampleRatio, "trace-sample-ratio", 1, "доля трассируемых запросов без родительского спана")
 	flag.Parse()
 
-	cfg := &config.GRPCServerConfig{
-		Port: *port,
+	if cfg.Faults.File != "" {
+		cfg.Faults.Enabled = true
+	}
+	if *allowedSubjects != "" {
+		cfg.TLS.AllowedSubjects = strings.Split(*allowedSubjects, ",")
+	}
+	if *adminSubjects != "" {
+		cfg.Admin.Subjects = strings.Split(*adminSubjects, ",")
 	}
 
 	zlog.Logger.Info().Msgf("cfg: %+v", cfg)
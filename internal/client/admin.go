@@ -0,0 +1,137 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+
+	pba "github.com/sunr3d/quorum-grep/proto/adminsvc"
+)
+
+// ServerStatus - ответ AdminService одного сервера.
+// При ошибке Info и Stats могут быть nil.
+type ServerStatus struct {
+	Server string
+	Info   *pba.ServerInfo
+	Stats  *pba.ServerStats
+	Err    error
+}
+
+// Servers - сведения и статистика всех серверов из discovery,
+// порядок сохраняется. Серверы опрашиваются параллельно.
+func (c *Client) Servers(ctx context.Context) []ServerStatus {
+	members := c.discovery.Servers()
+	statuses := make([]ServerStatus, len(members))
+
+	var wg sync.WaitGroup
+	for i, server := range members {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			statuses[i] = c.serverStatus(ctx, server)
+		}()
+	}
+	wg.Wait()
+
+	return statuses
+}
+
+func (c *Client) serverStatus(ctx context.Context, server string) ServerStatus {
+	st := ServerStatus{Server: server}
+
+	admin, err := c.adminClient(server)
+	if err != nil {
+		st.Err = err
+		return st
+	}
+
+	ctx, cancel := context.WithTimeout(c.adminContext(ctx), c.timeout)
+	defer cancel()
+
+	if st.Info, err = admin.GetInfo(ctx, &pba.GetInfoRequest{}); err != nil {
+		st.Err = fmt.Errorf("GetInfo: %w", fromStatus(err))
+		return st
+	}
+	if st.Stats, err = admin.GetStats(ctx, &pba.GetStatsRequest{}); err != nil {
+		st.Err = fmt.Errorf("GetStats: %w", fromStatus(err))
+	}
+
+	return st
+}
+
+// Drain - вывод сервера из работы. С wait ожидает завершения начатых
+// на сервере чанков, но не дольше таймаута клиента.
+// Возвращает число чанков, оставшихся в обработке.
+func (c *Client) Drain(ctx context.Context, server string, wait bool) (int64, error) {
+	admin, err := c.adminClient(server)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(c.adminContext(ctx), c.timeout)
+	defer cancel()
+
+	resp, err := admin.Drain(ctx, &pba.DrainRequest{Wait: wait})
+	if err != nil {
+		return 0, fmt.Errorf("Drain: %w", fromStatus(err))
+	}
+
+	return resp.InFlight, nil
+}
+
+func (c *Client) adminClient(server string) (pba.AdminServiceClient, error) {
+	conn, err := c.conn(server)
+	if err != nil {
+		return nil, err
+	}
+
+	return pba.NewAdminServiceClient(conn), nil
+}
+
+func (c *Client) adminContext(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, clientIDHeader, c.clientID)
+}
+
+// WriteServersTable - таблица состояния серверов для mygrep servers.
+func WriteServersTable(w io.Writer, statuses []ServerStatus) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SERVER\tVERSION\tSTATE\tIN-FLIGHT\tCHUNKS\tFAILED\tUPTIME\tMAX-CONCURRENT\tERROR")
+
+	for _, st := range statuses {
+		version, state, inFlight, chunks, failed, uptime, maxConcurrent := "-", "недоступен", "-", "-", "-", "-", "-"
+		if st.Info != nil {
+			version = st.Info.Version
+			if st.Info.Limits != nil {
+				maxConcurrent = fmt.Sprint(st.Info.Limits.MaxConcurrentChunks)
+			}
+		}
+		if s := st.Stats; s != nil {
+			state = "работает"
+			if s.Draining {
+				state = "выводится"
+			}
+			inFlight = fmt.Sprint(s.InFlight)
+			chunks = fmt.Sprint(s.Chunks)
+			failed = fmt.Sprint(s.FailedChunks)
+			uptime = (time.Duration(s.UptimeMs) * time.Millisecond).Round(time.Second).String()
+		}
+		errMsg := ""
+		if st.Err != nil {
+			errMsg = st.Err.Error()
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			st.Server, version, state, inFlight, chunks, failed, uptime, maxConcurrent, errMsg)
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("tw.Flush: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package client
+
+import (
+	"context"
+	"os"
+)
+
+// TokenEnv - переменная окружения с токеном, если CLIENT.AUTH_TOKEN не задан.
+const TokenEnv = "QUORUM_GREP_TOKEN"
+
+// tokenCredentials - bearer токен в метаданных каждого запроса.
+type tokenCredentials struct {
+	token string
+}
+
+// newTokenCredentials - токен из конфигурации или окружения, nil если токена нет.
+func newTokenCredentials(configured string) *tokenCredentials {
+	token := configured
+	if token == "" {
+		token = os.Getenv(TokenEnv)
+	}
+	if token == "" {
+		return nil
+	}
+
+	return &tokenCredentials{token: token}
+}
+
+func (t *tokenCredentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + t.token}, nil
+}
+
+// RequireTransportSecurity - токен можно отправлять и без TLS,
+// чтобы не ломать локальный запуск; в сети следует включать CLIENT.TLS.
+func (t *tokenCredentials) RequireTransportSecurity() bool {
+	return false
+}
@@ -4,90 +4,327 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/user"
+	"slices"
 	"sort"
 	"sync"
 	"time"
 
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc/filters"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 
 	"github.com/sunr3d/quorum-grep/internal/config"
+	"github.com/sunr3d/quorum-grep/internal/ctxutil"
+	"github.com/sunr3d/quorum-grep/internal/discovery"
+	"github.com/sunr3d/quorum-grep/internal/interfaces/services"
+	"github.com/sunr3d/quorum-grep/internal/services/grepsvc"
+	"github.com/sunr3d/quorum-grep/internal/tlsutil"
+	"github.com/sunr3d/quorum-grep/internal/tracing"
 	"github.com/sunr3d/quorum-grep/models"
 	pbg "github.com/sunr3d/quorum-grep/proto/grepsvc"
 )
 
-const capacity = 1024
+const (
+	capacity = 1024
+
+	// clientIDHeader - заголовок с пользователем клиента для журнала сервера;
+	// лимиты сервер считает по сертификату, токену или IP-адресу.
+	clientIDHeader = "x-client-id"
+	// maxRateLimitRetries - сколько раз чанк ждет RetryInfo, когда лимит
+	// запросов исчерпан на всех серверах.
+	maxRateLimitRetries = 3
+)
 
 type Client struct {
-	servers   []string
-	quorum    int
+	discovery discovery.Discovery
+	quorum    *quorumPolicy
 	timeout   time.Duration
 	chunkSize int
+	clientID  string
+	health    *healthChecker
+	hedge     *hedgePolicy
+	local     services.GrepService
+	verify    *verifier
+	fallback  fallbackPolicy
+	counters  *counters
+	output    OutputFormatter
+
+	tracing bool
+	creds   credentials.TransportCredentials
+	token   *tokenCredentials
+	connsMu sync.Mutex
+	conns   map[string]*grpc.ClientConn
 }
 
 // New - конструктор Client.
-func New(cfg *config.Config) *Client {
+func New(cfg *config.Config) (*Client, error) {
 	timeout, _ := time.ParseDuration(cfg.Client.Timeout)
-	quorum := len(cfg.Client.ServerList)/2 + 1
+
+	quorum, err := newQuorumPolicy(cfg.Client.Quorum, cfg.Client.Discovery, cfg.Client.ServerList)
+	if err != nil {
+		return nil, fmt.Errorf("newQuorumPolicy: %w", err)
+	}
+
+	local := grepsvc.New()
+	verify, err := newVerifier(cfg.Client.Verify, local)
+	if err != nil {
+		return nil, fmt.Errorf("newVerifier: %w", err)
+	}
+
+	disc, err := discovery.New(cfg.Client.Discovery, cfg.Client.ServerList)
+	if err != nil {
+		return nil, fmt.Errorf("discovery.New: %w", err)
+	}
+
+	creds := insecure.NewCredentials()
+	tlsCfg, err := tlsutil.NewClientConfig(cfg.Client.TLS)
+	if err != nil {
+		disc.Close()
+		return nil, fmt.Errorf("tlsutil.NewClientConfig: %w", err)
+	}
+	if tlsCfg != nil {
+		creds = credentials.NewTLS(tlsCfg)
+	}
 
 	return &Client{
-		servers:   cfg.Client.ServerList,
+		discovery: disc,
 		quorum:    quorum,
 		timeout:   timeout,
 		chunkSize: cfg.Client.ChunkSize,
-	}
+		clientID:  clientID(cfg.Client.ClientID),
+		health:    newHealthChecker(cfg.Client.HealthCheck),
+		hedge:     newHedgePolicy(cfg.Client.Hedge),
+		local:     local,
+		verify:    verify,
+		fallback:  newFallbackPolicy(cfg.Client.Fallback),
+		counters:  newCounters(),
+		output:    newTextFormatter(os.Stdout),
+		tracing:   tracing.Enabled(cfg.Client.Tracing),
+		creds:     creds,
+		token:     newTokenCredentials(cfg.Client.AuthToken),
+		conns:     make(map[string]*grpc.ClientConn),
+	}, nil
 }
 
-// ProcessFile - обрабатывает файл.
-// Разбивает на чанки и отправляет на серверы.
-// Ожидает результатов от серверов и собирает их в один результат.
-// Выводит результат в консоль.
-func (c *Client) ProcessFile(filename string, opts models.GrepOptions) error {
-	lines, err := c.readInput(filename)
-	if err != nil {
-		return fmt.Errorf("readInput: %w", err)
+// Close - закрывает соединения с серверами и сохраняет репутацию серверов.
+func (c *Client) Close() error {
+	c.connsMu.Lock()
+	defer c.connsMu.Unlock()
+
+	var errs []error
+	if c.verify != nil {
+		if err := c.verify.rep.save(); err != nil {
+			errs = append(errs, fmt.Errorf("reputation.save: %w", err))
+		}
+	}
+	if err := c.discovery.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("discovery.Close: %w", err))
+	}
+	for server, conn := range c.conns {
+		if err := conn.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("conn.Close %s: %w", server, err))
+		}
+		delete(c.conns, server)
 	}
 
-	tasks := c.splitData(lines, len(c.servers), opts)
+	return errors.Join(errs...)
+}
 
-	results, errors := c.sendToServers(tasks)
+// ProcessFile - обрабатывает файл или stdin ("-") и выводит результат
+// в заданном формате (SetOutput).
+func (c *Client) ProcessFile(ctx context.Context, filename string, opts models.GrepOptions) error {
+	var r io.Reader = os.Stdin
+	if filename != "-" && filename != "" {
+		file, err := os.Open(filename)
+		if err != nil {
+			return fmt.Errorf("ошибка открытия файла %s: %w", filename, err)
+		}
+		defer file.Close()
+		r = file
+	}
 
-	out, err := c.waitForQuorum(results, errors)
+	res, err := c.Search(ctx, filename, r, opts)
 	if err != nil {
-		return fmt.Errorf("waitForQuorum: %w", err)
+		return err
 	}
 
-	c.printResults(out, opts)
+	if err := c.output.File(res); err != nil {
+		return fmt.Errorf("output.File: %w", err)
+	}
 
 	return nil
 }
 
-// readInput - читает входные данные из файла или stdin.
-func (c *Client) readInput(filename string) ([][]byte, error) {
-	var scanner *bufio.Scanner
+// Search - поиск в данных из r, name - имя для статистики и вывода.
+// Весь поиск, включая чтение и все попытки отправки чанков, укладывается
+// в бюджет TIMEOUT; отмена ctx прерывает запросы к серверам.
+// Разбивает на чанки и отправляет на серверы, кроме серверов на карантине.
+// Ожидает результатов от серверов, выборочно проверяет их локально
+// (VERIFY) и собирает в один результат. При FALLBACK чанки, которые
+// серверы не обработали, обрабатываются локально, и кворум не нужен.
+// Итоги поиска попадают в статистику клиента (Stats).
+func (c *Client) Search(ctx context.Context, name string, r io.Reader, opts models.GrepOptions) (res *FileResult, err error) {
+	ctx, span := tracer.Start(ctx, "client.Search", trace.WithAttributes(attribute.String("file", name)))
+	members := c.discovery.Servers()
+	if c.verify != nil {
+		members = c.verify.rep.trusted(members)
+	}
+	c.forgetServers(members)
+	quorum := c.quorum.required(members)
+	fileStats := FileStats{File: name, Quorum: quorum}
+	var outcomes []ChunkOutcome
+	start := time.Now()
+	defer func() {
+		endSpan(span, err)
+
+		fileStats.Duration = time.Since(start)
+		fileStats.Err = err
+		c.counters.file(fileStats, outcomes)
+	}()
+
+	if err := ValidateOptions(opts); err != nil {
+		return nil, fmt.Errorf("ValidateOptions: %w", err)
+	}
+
+	lines, err := c.readInput(ctx, name, r)
+	if err != nil {
+		return nil, fmt.Errorf("readInput: %w", err)
+	}
+	fileStats.LinesRead = int64(len(lines))
+	for _, line := range lines {
+		fileStats.BytesRead += int64(len(line)) + 1
+	}
+
+	// таймаут ограничивает только работу с серверами: медленный источник
+	// (tail -f, конвейер) читается, пока не закроется или не отменен ctx.
+	// Локальная обработка получает свой бюджет: зависшие серверы могли
+	// израсходовать таймаут целиком.
+	localCtx := ctx
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	fallback := c.fallback.applies(fileStats.BytesRead)
+	servers := c.health.serving(ctx, members, c.conn)
+	if !fallback && c.quorum.votes(servers) < quorum {
+		return nil, fmt.Errorf("%w: доступно серверов: %d из %d, голосов %d, для кворума нужно %d",
+			ErrNoQuorum, len(servers), len(members), c.quorum.votes(servers), quorum)
+	}
+
+	// без серверов вход обрабатывается локально одним чанком
+	tasks := c.splitData(ctx, lines, max(len(members), 1), opts)
 
-	if filename == "-" || filename == "" {
-		scanner = bufio.NewScanner(os.Stdin)
+	var results []models.Result
+	var errs []error
+	if len(servers) > 0 {
+		results, outcomes, errs = c.sendToServers(ctx, servers, tasks)
 	} else {
-		file, err := os.Open(filename)
-		if err != nil {
-			return nil, fmt.Errorf("ошибка открытия файла %s: %w", filename, err)
+		results, outcomes, errs = unsent(tasks)
+	}
+	fileStats.Chunks = len(tasks)
+
+	if c.verify != nil {
+		checks, mismatches := c.verify.check(ctx, tasks, results, outcomes, servers, c.callTask)
+		c.counters.spotChecks.Add(int64(checks))
+		c.counters.mismatches.Add(int64(mismatches))
+	}
+
+	serverVotes := c.quorum.chunkVotes(outcomes)
+	votes, need := serverVotes, quorum
+	if fallback {
+		fileStats.ChunksLocal = c.processLocally(localCtx, tasks, results, outcomes, errs)
+		// результат полон, только если обработаны все чанки
+		votes, need = 0, len(tasks)
+		for _, err := range errs {
+			if err == nil {
+				votes++
+			}
 		}
-		defer file.Close()
-		scanner = bufio.NewScanner(file)
 	}
+	for i := range outcomes {
+		outcomes[i].File = name
+		if outcomes[i].Err == nil {
+			fileStats.ChunksOK++
+		}
+	}
+
+	out, err := c.waitForQuorum(ctx, results, errs, votes, need)
+	if err != nil {
+		return nil, fmt.Errorf("waitForQuorum: %w", err)
+	}
+	fileStats.QuorumReached = serverVotes >= quorum
+	fileStats.Matches = len(out)
+
+	return &FileResult{
+		File:    name,
+		Options: opts,
+		Matches: out,
+		Lines:   lines,
+		Elapsed: time.Since(start),
+	}, nil
+}
+
+// SetQuorum - сколько чанков должно быть обработано успешно вместо
+// QUORUM из конфига; n от 1 до текущего числа серверов. Если серверов
+// потом станет меньше n, поиск завершится ошибкой ErrNoQuorum.
+func (c *Client) SetQuorum(n int) error {
+	if members := len(c.discovery.Servers()); n < 1 || n > members {
+		return fmt.Errorf("кворум %d вне диапазона 1..%d", n, members)
+	}
+	c.quorum = &quorumPolicy{policy: QuorumCount, count: n}
+
+	return nil
+}
+
+// SetOutput - формат вывода результатов вместо текста в stdout
+// (см. NewFormatter). Итоги по всем файлам формат пишет в Finish.
+func (c *Client) SetOutput(f OutputFormatter) {
+	c.output = f
+}
+
+// Finish - завершение вывода после обработки всех файлов.
+func (c *Client) Finish() error {
+	if err := c.output.Close(); err != nil {
+		return fmt.Errorf("output.Close: %w", err)
+	}
+
+	return nil
+}
 
-	lines := make([][]byte, 0, capacity)
+// readInput - читает строки из r, name - имя входа для ошибок.
+// Между строками проверяется отмена ctx.
+func (c *Client) readInput(ctx context.Context, name string, r io.Reader) (lines [][]byte, err error) {
+	ctx, span := tracer.Start(ctx, "client.readInput")
+	defer func() {
+		span.SetAttributes(attribute.Int("lines", len(lines)))
+		endSpan(span, err)
+	}()
+
+	scanner := bufio.NewScanner(r)
+	lines = make([][]byte, 0, capacity)
 	for scanner.Scan() {
+		if len(lines)%capacity == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, fmt.Errorf("чтение %s прервано: %w", name, err)
+			}
+		}
+
 		line := make([]byte, len(scanner.Bytes()))
 		copy(line, scanner.Bytes())
 		lines = append(lines, line)
 	}
 
 	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("ошибка чтения %s: %w", filename, err)
+		return nil, fmt.Errorf("ошибка чтения %s: %w", name, err)
 	}
 
 	return lines, nil
@@ -95,7 +332,10 @@ func (c *Client) readInput(filename string) ([][]byte, error) {
 
 // splitData - разбивает данные на чанки.
 // Обрабатывает перекрытие контекста.
-func (c *Client) splitData(lines [][]byte, numServers int, opts models.GrepOptions) []models.Task {
+func (c *Client) splitData(ctx context.Context, lines [][]byte, numServers int, opts models.GrepOptions) []models.Task {
+	_, span := tracer.Start(ctx, "client.splitData", trace.WithAttributes(attribute.Int("chunks", numServers)))
+	defer span.End()
+
 	lineLen := len(lines)
 	chunkSize := c.chunkSize
 	if chunkSize <= 0 || chunkSize > lineLen {
@@ -173,12 +413,17 @@ func (c *Client) buildRequest(id int, task models.Task) *pbg.ChunkRequest {
 }
 
 // sendToServers - отправляет чанки на серверы в горутинах.
-// Устанавливает соединение с сервером и отправляет запрос.
+// Чанки распределяются только между servers - серверами в состоянии SERVING.
 // Ожидает результатов от серверов и собирает их в один результат.
-// Возвращает результаты и ошибки.
-func (c *Client) sendToServers(tasks []models.Task) ([]models.Result, []error) {
+// Возвращает результаты, исходы чанков и ошибки.
+func (c *Client) sendToServers(
+	ctx context.Context,
+	servers []string,
+	tasks []models.Task,
+) ([]models.Result, []ChunkOutcome, []error) {
 	results := make([]models.Result, len(tasks))
-	errors := make([]error, len(tasks))
+	outcomes := make([]ChunkOutcome, len(tasks))
+	errs := make([]error, len(tasks))
 
 	var wg sync.WaitGroup
 
@@ -187,71 +432,282 @@ func (c *Client) sendToServers(tasks []models.Task) ([]models.Result, []error) {
 		go func(i int, task models.Task) {
 			defer wg.Done()
 
-			server := c.servers[i%len(c.servers)]
-
-			conn, err := grpc.NewClient(server, grpc.WithTransportCredentials(insecure.NewCredentials()))
+			result, err := c.dispatch(ctx, servers, i, c.buildRequest(i, task), &outcomes[i])
 			if err != nil {
-				errors[i] = fmt.Errorf("не удалось подключиться к серверу %s: %w", server, err)
+				errs[i] = err
 				return
 			}
-			defer conn.Close()
 
-			client := pbg.NewGrepServiceClient(conn)
+			result.TaskIndex = i
+			results[i] = result
+		}(i, task)
+	}
+
+	wg.Wait()
+
+	return results, outcomes, errs
+}
+
+// attempt - результат одной попытки обработки чанка.
+type attempt struct {
+	result models.Result
+	err    error
+	server string
+	hedged bool
+	took   time.Duration
+}
+
+// dispatch - обработка одного чанка.
+// При временной ошибке чанк отправляется на следующий сервер, неустранимые
+// ошибки (ErrPermanent) не повторяются. Если включен hedging и ответа нет
+// дольше порога, дубликат отправляется на следующий сервер; берется первый
+// успешный ответ, остальные запросы отменяются.
+// Все попытки используют дедлайн ctx, а не отдельный таймаут на каждую.
+func (c *Client) dispatch(
+	ctx context.Context,
+	servers []string,
+	i int,
+	req *pbg.ChunkRequest,
+	outcome *ChunkOutcome,
+) (result models.Result, err error) {
+	ctx, span := tracer.Start(ctx, "client.dispatch", trace.WithAttributes(
+		attribute.Int("chunk.index", i),
+		attribute.Int("chunk.bytes", len(req.Data)),
+	))
+	outcome.Index = i
+	defer func() {
+		outcome.Err = err
+		endSpan(span, err)
+	}()
+
+	attemptCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	attempts := make(chan attempt, len(servers)*(maxRateLimitRetries+1))
+	next, inflight, hedges, rateRetries := 0, 0, 0, 0
+	var wait time.Duration
+
+	launch := func(hedged bool) {
+		server := servers[(i+next)%len(servers)]
+		next++
+		inflight++
+		outcome.Attempts++
+
+		go func() {
+			start := time.Now()
+			result, err := c.callServer(attemptCtx, server, req)
+			attempts <- attempt{result: result, err: err, server: server, hedged: hedged, took: time.Since(start)}
+		}()
+	}
+
+	var hedgeTimer *time.Timer
+	var hedgeC <-chan time.Time
+	if c.hedge.enabled && len(servers) > 1 && c.hedge.maxHedges > 0 {
+		hedgeTimer = time.NewTimer(c.hedge.threshold())
+		defer hedgeTimer.Stop()
+		hedgeC = hedgeTimer.C
+	}
+
+	launch(false)
+
+	var lastErr error
+	for inflight > 0 {
+		select {
+		case a := <-attempts:
+			inflight--
+			c.counters.attempt(a.server, a.took, a.err)
+			outcome.Server = a.server
+
+			if a.err == nil {
+				c.hedge.latencies.observe(a.took)
+				if a.hedged {
+					c.counters.hedgeWins.Add(1)
+					outcome.Hedged = true
+				}
+				return a.result, nil
+			}
+
+			lastErr = fmt.Errorf("ошибка при обработке куска %d на сервере %s: %w", i, a.server, a.err)
+			if errors.Is(a.err, ErrPermanent) {
+				return models.Result{}, lastErr
+			}
 
-			req := c.buildRequest(i, task)
+			// бюджет исчерпан или поиск отменен: повторять бессмысленно
+			if ctx.Err() != nil {
+				return models.Result{}, fmt.Errorf("%w: %w", lastErr, ctx.Err())
+			}
 
-			ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
-			defer cancel()
+			if d, ok := retryAfter(a.err); ok && (wait == 0 || d < wait) {
+				wait = d
+			}
 
-			resp, err := client.ProcessChunk(ctx, req)
-			if err != nil {
-				errors[i] = fmt.Errorf("ошибка при обработке куска %d на сервере %s: %w", i, server, err)
-				return
+			// пока есть запрос в полете, новый сервер не нужен: ждем его ответа
+			if inflight > 0 {
+				continue
 			}
 
-			matches := make([]models.Match, len(resp.Matches))
-			for i, match := range resp.Matches {
-				matches[i] = models.Match{
-					Content:    match.Content,
-					LineNumber: match.LineNumber,
+			switch {
+			case next < len(servers):
+				c.counters.retries.Add(1)
+				launch(false)
+			case wait > 0 && rateRetries < maxRateLimitRetries:
+				// все серверы ограничили клиента: ждем, сколько просил сервер
+				rateRetries++
+				c.counters.rateLimitWaits.Add(1)
+				if err := ctxutil.Sleep(ctx, wait); err != nil {
+					return models.Result{}, fmt.Errorf("%w: %w", lastErr, err)
 				}
+				wait = 0
+				c.counters.retries.Add(1)
+				launch(false)
+			default:
 			}
 
-			results[i] = models.Result{
-				Matches:    matches,
-				MatchCount: int(resp.MatchCount),
-				Error:      resp.Error,
-				TaskIndex:  i,
+		case <-hedgeC:
+			if next >= len(servers) {
+				hedgeC = nil
+				continue
 			}
-		}(i, task)
+
+			launch(true)
+			hedges++
+			c.counters.hedges.Add(1)
+
+			if hedges < c.hedge.maxHedges {
+				hedgeTimer.Reset(c.hedge.threshold())
+			} else {
+				hedgeC = nil
+			}
+		}
 	}
 
-	wg.Wait()
+	return models.Result{}, lastErr
+}
+
+// conn - соединение с сервером, создается один раз и переиспользуется.
+func (c *Client) conn(server string) (*grpc.ClientConn, error) {
+	c.connsMu.Lock()
+	defer c.connsMu.Unlock()
+
+	if conn, ok := c.conns[server]; ok {
+		return conn, nil
+	}
+
+	dialOpts := []grpc.DialOption{grpc.WithTransportCredentials(c.creds)}
+	if c.tracing {
+		dialOpts = append(dialOpts, grpc.WithStatsHandler(otelgrpc.NewClientHandler(
+			otelgrpc.WithFilter(filters.Not(filters.HealthCheck())),
+		)))
+	}
+	if c.token != nil {
+		dialOpts = append(dialOpts, grpc.WithPerRPCCredentials(c.token))
+	}
+
+	conn, err := grpc.NewClient(server, dialOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось подключиться к серверу %s: %w", server, err)
+	}
+	c.conns[server] = conn
+
+	return conn, nil
+}
+
+// forgetServers - закрывает соединения с серверами, которых больше нет
+// в списке members.
+func (c *Client) forgetServers(members []string) {
+	c.connsMu.Lock()
+	defer c.connsMu.Unlock()
+
+	for server, conn := range c.conns {
+		if !slices.Contains(members, server) {
+			conn.Close()
+			delete(c.conns, server)
+		}
+	}
+}
+
+// callTask - отправляет чанк на сервер вне основной рассылки (сверка VERIFY).
+func (c *Client) callTask(ctx context.Context, server string, task *models.Task) (models.Result, error) {
+	return c.callServer(ctx, server, c.buildRequest(task.Index, *task))
+}
+
+// callServer - отправляет один запрос на сервер.
+func (c *Client) callServer(ctx context.Context, server string, req *pbg.ChunkRequest) (models.Result, error) {
+	conn, err := c.conn(server)
+	if err != nil {
+		return models.Result{}, err
+	}
+
+	client := pbg.NewGrepServiceClient(conn)
+
+	ctx = metadata.AppendToOutgoingContext(ctx, clientIDHeader, c.clientID)
+
+	resp, err := client.ProcessChunk(ctx, req)
+	if err != nil {
+		return models.Result{}, fromStatus(err)
+	}
 
-	return results, errors
+	matches := make([]models.Match, len(resp.Matches))
+	for i, match := range resp.Matches {
+		matches[i] = models.Match{
+			Content:    match.Content,
+			LineNumber: match.LineNumber,
+		}
+	}
+
+	return models.Result{
+		Matches:    matches,
+		MatchCount: int(resp.MatchCount),
+	}, nil
 }
 
 // waitForQuorum - ожидает результатов от серверов и собирает их в один результат.
+// Неустранимая ошибка возвращается один раз, а не для каждого чанка.
+// Голосов успешных чанков votes должно быть не меньше quorum.
 // Возвращает результаты и ошибки.
-func (c *Client) waitForQuorum(results []models.Result, errors []error) ([]models.Match, error) {
-	success := 0
+func (c *Client) waitForQuorum(
+	ctx context.Context,
+	results []models.Result,
+	errs []error,
+	votes, quorum int,
+) (out []models.Match, err error) {
+	_, span := tracer.Start(ctx, "client.waitForQuorum")
+	defer func() {
+		span.SetAttributes(attribute.Int("matches", len(out)))
+		endSpan(span, err)
+	}()
+
+	for _, err := range errs {
+		if errors.Is(err, ErrPermanent) {
+			return nil, err
+		}
+	}
+
 	seen := make(map[int64]bool)
-	var out []models.Match
+	var firstErr error
 
 	for i, result := range results {
-		if errors[i] == nil {
-			for _, match := range result.Matches {
-				if !seen[match.LineNumber] {
-					seen[match.LineNumber] = true
-					out = append(out, match)
-				}
+		if errs[i] != nil {
+			if firstErr == nil {
+				firstErr = errs[i]
+			}
+			continue
+		}
+
+		for _, match := range result.Matches {
+			if !seen[match.LineNumber] {
+				seen[match.LineNumber] = true
+				out = append(out, match)
 			}
-			success++
 		}
 	}
 
-	if success < c.quorum {
-		return nil, fmt.Errorf("недостаточно успешных результатов")
+	if votes < quorum {
+		if firstErr != nil {
+			return nil, fmt.Errorf("%w: недостаточно успешных результатов (голосов %d из %d): %w",
+				ErrNoQuorum, votes, quorum, firstErr)
+		}
+		return nil, fmt.Errorf("%w: недостаточно успешных результатов (голосов %d из %d)", ErrNoQuorum, votes, quorum)
 	}
 
 	sort.Slice(out, func(i, j int) bool {
@@ -261,17 +717,17 @@ func (c *Client) waitForQuorum(results []models.Result, errors []error) ([]model
 	return out, nil
 }
 
-// printResults - выводит результаты в консоль.
-func (c *Client) printResults(matches []models.Match, opts models.GrepOptions) {
-	if opts.Count {
-		fmt.Println(len(matches))
-	} else {
-		for _, match := range matches {
-			if opts.LineNum {
-				fmt.Printf("%d:%s\n", match.LineNumber, string(match.Content))
-			} else {
-				fmt.Printf("%s\n", string(match.Content))
-			}
-		}
+// clientID - идентификатор клиента для серверов: из конфига или user@host.
+func clientID(configured string) string {
+	if configured != "" {
+		return configured
+	}
+
+	host, _ := os.Hostname()
+	name := "unknown"
+	if u, err := user.Current(); err == nil {
+		name = u.Username
 	}
+
+	return name + "@" + host
 }
@@ -0,0 +1,213 @@
+package client
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sunr3d/quorum-grep/internal/config"
+	"github.com/sunr3d/quorum-grep/internal/localcluster"
+	"github.com/sunr3d/quorum-grep/internal/server"
+	"github.com/sunr3d/quorum-grep/models"
+)
+
+const clusterInput = "error 1\nok\nerror 3\nok\nerror 5\nerror 6\n"
+
+func TestClientCluster(t *testing.T) {
+	hung := server.MethodFaults{Delay: 5 * time.Second, DelayProbability: 1}
+
+	tests := []struct {
+		name    string
+		faults  []server.MethodFaults
+		crash   bool
+		modify  func(*config.ClientConfig)
+		wantErr error
+		check   func(t *testing.T, stats Stats)
+	}{
+		{
+			name: "исправный кластер",
+			check: func(t *testing.T, stats Stats) {
+				assert.Zero(t, stats.Retries)
+			},
+		},
+		{
+			name:   "ошибки узла - повтор на другом",
+			faults: []server.MethodFaults{{ErrorProbability: 1}},
+			check: func(t *testing.T, stats Stats) {
+				assert.NotZero(t, stats.Retries)
+				assert.Equal(t, 3, stats.Files[0].ChunksOK)
+			},
+		},
+		{
+			name:  "падение узла посреди запроса",
+			crash: true,
+			check: func(t *testing.T, stats Stats) {
+				assert.Equal(t, int64(1), stats.Retries)
+				assert.Equal(t, 3, stats.Files[0].ChunksOK)
+			},
+		},
+		{
+			name:    "ошибки на всех узлах",
+			faults:  []server.MethodFaults{{ErrorProbability: 1}, {ErrorProbability: 1}, {ErrorProbability: 1}},
+			wantErr: ErrNoQuorum,
+		},
+		{
+			name:   "неверные результаты ловит проверка",
+			faults: []server.MethodFaults{{OmitProbability: 1}, {OmitProbability: 1}, {OmitProbability: 1}},
+			modify: func(c *config.ClientConfig) {
+				c.Verify = config.VerifyConfig{
+					Enabled:         true,
+					SpotCheckRatio:  1,
+					StateFile:       filepath.Join(t.TempDir(), "reputation.json"),
+					MaxDisagreement: 0.5,
+					MinChecks:       10,
+					Quarantine:      "1h",
+				}
+			},
+			check: func(t *testing.T, stats Stats) {
+				assert.Equal(t, int64(3), stats.Mismatches)
+			},
+		},
+		{
+			name:   "зависшие узлы - локальная обработка после таймаута",
+			faults: []server.MethodFaults{hung, hung, hung},
+			modify: func(c *config.ClientConfig) {
+				c.Timeout = "200ms"
+				c.Fallback = config.FallbackConfig{Enabled: true}
+			},
+			check: func(t *testing.T, stats Stats) {
+				assert.Equal(t, 3, stats.Files[0].ChunksLocal)
+				assert.False(t, stats.Files[0].QuorumReached)
+			},
+		},
+		{
+			name:   "медленный узел - дубль",
+			faults: []server.MethodFaults{{Delay: 2 * time.Second, DelayProbability: 1}},
+			modify: func(c *config.ClientConfig) {
+				c.Hedge = config.HedgeConfig{Enabled: true, Delay: "50ms", MaxHedges: 1}
+			},
+			check: func(t *testing.T, stats Stats) {
+				assert.Equal(t, int64(1), stats.HedgeWins)
+				assert.Less(t, stats.Files[0].Duration, time.Second)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster, err := localcluster.Start(3, nil)
+			require.NoError(t, err)
+			defer cluster.Close()
+			for i, f := range tt.faults {
+				require.NoError(t, cluster.Node(i).SetFaults(f))
+			}
+			if tt.crash {
+				cluster.Node(0).CrashAfter(1)
+			}
+
+			cfg := &config.Config{Client: config.ClientConfig{
+				ServerList: cluster.Addrs(),
+				Timeout:    "5s",
+			}}
+			if tt.modify != nil {
+				tt.modify(&cfg.Client)
+			}
+			c, err := New(cfg)
+			require.NoError(t, err)
+			defer c.Close()
+
+			res, err := c.Search(context.Background(), "input", strings.NewReader(clusterInput), models.GrepOptions{Pattern: "error"})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			var lines []int64
+			for _, m := range res.Matches {
+				lines = append(lines, m.LineNumber)
+			}
+			assert.Equal(t, []int64{1, 3, 5, 6}, lines)
+			if tt.check != nil {
+				tt.check(t, c.Stats())
+			}
+		})
+	}
+}
+
+// slowReader - источник, который отдает данные дольше таймаута клиента.
+type slowReader struct {
+	r     io.Reader
+	delay time.Duration
+}
+
+func (r *slowReader) Read(p []byte) (int, error) {
+	time.Sleep(r.delay)
+	return r.r.Read(p)
+}
+
+func TestClientSlowInput(t *testing.T) {
+	cluster, err := localcluster.Start(3, nil)
+	require.NoError(t, err)
+	defer cluster.Close()
+
+	c, err := New(&config.Config{Client: config.ClientConfig{
+		ServerList: cluster.Addrs(),
+		Timeout:    "300ms",
+	}})
+	require.NoError(t, err)
+	defer c.Close()
+
+	// чтение входа не расходует таймаут работы с серверами
+	r := &slowReader{r: strings.NewReader(clusterInput), delay: 200 * time.Millisecond}
+	res, err := c.Search(context.Background(), "input", r, models.GrepOptions{Pattern: "error"})
+	require.NoError(t, err)
+	assert.Len(t, res.Matches, 4)
+}
+
+func TestClientServers(t *testing.T) {
+	cluster, err := localcluster.Start(2, &config.GRPCServerConfig{
+		Admin: config.ServerAdminConfig{Enabled: true},
+	})
+	require.NoError(t, err)
+	defer cluster.Close()
+
+	c, err := New(&config.Config{Client: config.ClientConfig{
+		ServerList: append(cluster.Addrs(), "127.0.0.1:1"),
+		Timeout:    "2s",
+	}})
+	require.NoError(t, err)
+	defer c.Close()
+
+	inFlight, err := c.Drain(context.Background(), cluster.Node(1).Addr(), true)
+	require.NoError(t, err)
+	assert.Zero(t, inFlight)
+
+	statuses := c.Servers(context.Background())
+	require.Len(t, statuses, 3)
+	byServer := make(map[string]ServerStatus)
+	for _, st := range statuses {
+		byServer[st.Server] = st
+	}
+
+	healthy, drained, down := byServer[cluster.Node(0).Addr()], byServer[cluster.Node(1).Addr()], byServer["127.0.0.1:1"]
+	require.NoError(t, healthy.Err)
+	assert.Contains(t, healthy.Info.Engines, "regexp")
+	assert.False(t, healthy.Stats.Draining)
+	require.NoError(t, drained.Err)
+	assert.True(t, drained.Stats.Draining)
+	assert.Error(t, down.Err, "сервер недоступен")
+
+	var out strings.Builder
+	require.NoError(t, WriteServersTable(&out, []ServerStatus{drained, down}))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Contains(t, lines[1], "выводится")
+	assert.Contains(t, lines[2], "недоступен")
+}
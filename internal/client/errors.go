@@ -0,0 +1,106 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/sunr3d/quorum-grep/models"
+)
+
+// ErrPermanent - ошибка, которую бессмысленно повторять на другом сервере.
+var ErrPermanent = errors.New("неустранимая ошибка")
+
+// ErrNoQuorum - успешно ответивших или доступных серверов меньше кворума.
+var ErrNoQuorum = errors.New("кворум не достигнут")
+
+// remoteError - ошибка, полученная от сервера.
+// Сообщение берется из gRPC status, а errors.Is работает
+// с типизированной ошибкой и с ErrPermanent.
+type remoteError struct {
+	msg        string
+	typed      error
+	permanent  bool
+	retryAfter time.Duration
+}
+
+func (e *remoteError) Error() string {
+	return e.msg
+}
+
+func (e *remoteError) Unwrap() error {
+	return e.typed
+}
+
+func (e *remoteError) Is(target error) bool {
+	return target == ErrPermanent && e.permanent
+}
+
+// fromStatus - восстановление типизированной ошибки из gRPC status.
+// Неустранимые ошибки (некорректный шаблон, превышение лимитов,
+// неподдерживаемый запрос) помечаются как ErrPermanent.
+func fromStatus(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	remote := &remoteError{
+		msg:       fmt.Sprintf("%s: %s", st.Code(), st.Message()),
+		typed:     err,
+		permanent: !isRetryableCode(st.Code()),
+	}
+
+	for _, detail := range st.Details() {
+		if retry, ok := detail.(*errdetails.RetryInfo); ok {
+			remote.retryAfter = retry.GetRetryDelay().AsDuration()
+			continue
+		}
+
+		info, ok := detail.(*errdetails.ErrorInfo)
+		if !ok || info.Domain != models.ErrorDomain {
+			continue
+		}
+		if typed := models.ErrorByReason(info.Reason); typed != nil {
+			remote.msg = st.Message()
+			remote.typed = typed
+			// перегрузка или лимит запросов одного сервера
+			// не мешают обработать чанк на другом
+			if errors.Is(typed, models.ErrOverloaded) || errors.Is(typed, models.ErrRateLimited) {
+				remote.permanent = false
+			}
+		}
+	}
+
+	return remote
+}
+
+// retryAfter - через сколько сервер просит повторить запрос (google.rpc.RetryInfo).
+func retryAfter(err error) (time.Duration, bool) {
+	var remote *remoteError
+	if errors.As(err, &remote) && remote.retryAfter > 0 {
+		return remote.retryAfter, true
+	}
+
+	return 0, false
+}
+
+// isRetryableCode - можно ли повторить запрос с таким кодом на другом сервере.
+func isRetryableCode(code codes.Code) bool {
+	switch code {
+	case codes.InvalidArgument,
+		codes.ResourceExhausted,
+		codes.FailedPrecondition,
+		codes.OutOfRange,
+		codes.Unimplemented,
+		codes.PermissionDenied,
+		codes.Unauthenticated:
+		return false
+	default:
+		return true
+	}
+}
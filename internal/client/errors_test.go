@@ -0,0 +1,79 @@
+package client
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/sunr3d/quorum-grep/models"
+)
+
+func TestFromStatus(t *testing.T) {
+	withReason := func(code codes.Code, reason string) error {
+		st, err := status.New(code, "msg").WithDetails(&errdetails.ErrorInfo{
+			Reason: reason,
+			Domain: models.ErrorDomain,
+		})
+		require.NoError(t, err)
+		return st.Err()
+	}
+
+	tests := []struct {
+		name      string
+		err       error
+		permanent bool
+		is        error
+	}{
+		{
+			name:      "некорректный шаблон",
+			err:       withReason(codes.InvalidArgument, models.ReasonInvalidPattern),
+			permanent: true,
+			is:        models.ErrInvalidPattern,
+		},
+		{
+			name:      "превышен лимит",
+			err:       withReason(codes.ResourceExhausted, models.ReasonLimitExceeded),
+			permanent: true,
+			is:        models.ErrLimitExceeded,
+		},
+		{
+			name: "сервер перегружен",
+			err:  withReason(codes.ResourceExhausted, models.ReasonOverloaded),
+			is:   models.ErrOverloaded,
+		},
+		{
+			name: "отмена на сервере",
+			err:  withReason(codes.DeadlineExceeded, models.ReasonCancelled),
+			is:   models.ErrCancelled,
+		},
+		{
+			name: "сервер недоступен",
+			err:  status.Error(codes.Unavailable, "connection refused"),
+		},
+		{
+			name:      "без деталей",
+			err:       status.Error(codes.Unimplemented, "unknown method"),
+			permanent: true,
+		},
+		{
+			name: "не gRPC ошибка",
+			err:  errors.New("boom"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := fromStatus(tt.err)
+
+			assert.Equal(t, tt.permanent, errors.Is(err, ErrPermanent))
+			if tt.is != nil {
+				assert.ErrorIs(t, err, tt.is)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package client
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/sunr3d/quorum-grep/internal/config"
+	"github.com/sunr3d/quorum-grep/models"
+)
+
+// errNoServers - чанк не отправлен: нет доступных серверов.
+var errNoServers = errors.New("нет доступных серверов")
+
+// fallbackPolicy - когда чанки, не обработанные серверами, обрабатываются
+// локально тем же grepsvc. Результат тогда полный, даже если кворум
+// не достигнут или серверов нет вовсе.
+type fallbackPolicy struct {
+	enabled      bool
+	autoMaxBytes int64
+}
+
+func newFallbackPolicy(cfg config.FallbackConfig) fallbackPolicy {
+	return fallbackPolicy{enabled: cfg.Enabled, autoMaxBytes: cfg.AutoMaxBytes}
+}
+
+// applies - включена ли локальная обработка для входа размером size байт.
+func (p fallbackPolicy) applies(size int64) bool {
+	return p.enabled || (p.autoMaxBytes > 0 && size <= p.autoMaxBytes)
+}
+
+// unsent - исходы чанков, которые некому отправить.
+func unsent(tasks []models.Task) ([]models.Result, []ChunkOutcome, []error) {
+	outcomes := make([]ChunkOutcome, len(tasks))
+	errs := make([]error, len(tasks))
+	for i := range tasks {
+		outcomes[i] = ChunkOutcome{Index: i, Err: errNoServers}
+		errs[i] = errNoServers
+	}
+
+	return make([]models.Result, len(tasks)), outcomes, errs
+}
+
+// processLocally - локальная обработка чанков, которые серверы не обработали.
+// ctx не должен быть ограничен таймаутом работы с серверами: на обработку
+// отводится отдельный бюджет в c.timeout.
+// Неустранимые ошибки не обходятся: локально они повторились бы.
+// Возвращает число чанков, обработанных локально.
+func (c *Client) processLocally(
+	ctx context.Context,
+	tasks []models.Task,
+	results []models.Result,
+	outcomes []ChunkOutcome,
+	errs []error,
+) (n int) {
+	ctx, span := tracer.Start(ctx, "client.processLocally")
+	defer func() {
+		span.SetAttributes(attribute.Int("chunks", n))
+		span.End()
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	for i := range tasks {
+		if errs[i] == nil || errors.Is(errs[i], ErrPermanent) {
+			continue
+		}
+
+		local, err := c.local.ProcessChunk(ctx, &tasks[i])
+		if err != nil {
+			continue
+		}
+
+		results[i] = models.Result{Matches: local.Matches, MatchCount: local.MatchCount, TaskIndex: i}
+		errs[i] = nil
+		outcomes[i].Err = nil
+		outcomes[i].Local = true
+		n++
+	}
+
+	return n
+}
@@ -0,0 +1,117 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/sunr3d/quorum-grep/internal/config"
+	pbg "github.com/sunr3d/quorum-grep/proto/grepsvc"
+)
+
+// healthChecker - кэш состояния серверов по протоколу grpc.health.v1.
+type healthChecker struct {
+	enabled  bool
+	interval time.Duration
+	timeout  time.Duration
+
+	mu     sync.Mutex
+	states map[string]healthState
+}
+
+type healthState struct {
+	serving   bool
+	checkedAt time.Time
+}
+
+// newHealthChecker - конструктор healthChecker.
+func newHealthChecker(cfg config.HealthCheckConfig) *healthChecker {
+	interval, _ := time.ParseDuration(cfg.Interval)
+	timeout, _ := time.ParseDuration(cfg.Timeout)
+
+	return &healthChecker{
+		enabled:  cfg.Enabled,
+		interval: interval,
+		timeout:  timeout,
+		states:   make(map[string]healthState),
+	}
+}
+
+// serving - серверы в состоянии SERVING, порядок сохраняется.
+// Состояние, проверенное раньше чем interval назад, проверяется заново
+// параллельно для всех серверов.
+func (h *healthChecker) serving(
+	ctx context.Context,
+	servers []string,
+	conn func(string) (*grpc.ClientConn, error),
+) []string {
+	if !h.enabled {
+		return servers
+	}
+
+	now := time.Now()
+
+	var wg sync.WaitGroup
+	for _, server := range servers {
+		h.mu.Lock()
+		state, ok := h.states[server]
+		h.mu.Unlock()
+
+		if ok && now.Sub(state.checkedAt) < h.interval {
+			continue
+		}
+
+		wg.Add(1)
+		go func(server string) {
+			defer wg.Done()
+
+			serving := h.probe(ctx, server, conn)
+			if ctx.Err() != nil {
+				return
+			}
+
+			h.mu.Lock()
+			h.states[server] = healthState{serving: serving, checkedAt: time.Now()}
+			h.mu.Unlock()
+		}(server)
+	}
+	wg.Wait()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	out := make([]string, 0, len(servers))
+	for _, server := range servers {
+		if h.states[server].serving {
+			out = append(out, server)
+		}
+	}
+
+	return out
+}
+
+// probe - проверка одного сервера.
+// Сервер без сервиса health считается доступным.
+func (h *healthChecker) probe(ctx context.Context, server string, conn func(string) (*grpc.ClientConn, error)) bool {
+	cc, err := conn(server)
+	if err != nil {
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	resp, err := healthpb.NewHealthClient(cc).Check(ctx, &healthpb.HealthCheckRequest{
+		Service: pbg.GrepService_ServiceDesc.ServiceName,
+	})
+	if err != nil {
+		return status.Code(err) == codes.Unimplemented
+	}
+
+	return resp.Status == healthpb.HealthCheckResponse_SERVING
+}
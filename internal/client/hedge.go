@@ -0,0 +1,91 @@
+package client
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/sunr3d/quorum-grep/internal/config"
+)
+
+const (
+	// latencyWindow - сколько последних задержек учитывается при расчете перцентиля.
+	latencyWindow = 256
+	// minLatencySamples - минимум наблюдений, после которого используется перцентиль.
+	minLatencySamples = 10
+)
+
+// hedgePolicy - правила отправки дублирующих (hedged) запросов.
+// Если чанк не обработан за threshold, он дублируется на другой сервер,
+// берется первый успешный ответ, остальные запросы отменяются.
+type hedgePolicy struct {
+	enabled    bool
+	delay      time.Duration
+	percentile float64
+	maxHedges  int
+	latencies  *latencyTracker
+}
+
+// newHedgePolicy - конструктор hedgePolicy.
+func newHedgePolicy(cfg config.HedgeConfig) *hedgePolicy {
+	delay, _ := time.ParseDuration(cfg.Delay)
+
+	return &hedgePolicy{
+		enabled:    cfg.Enabled,
+		delay:      delay,
+		percentile: cfg.Percentile,
+		maxHedges:  cfg.MaxHedges,
+		latencies:  &latencyTracker{},
+	}
+}
+
+// threshold - через сколько после отправки чанка отправлять дубликат.
+// Пока наблюдений мало, используется фиксированная задержка delay.
+func (h *hedgePolicy) threshold() time.Duration {
+	if h.percentile > 0 {
+		if p, ok := h.latencies.percentile(h.percentile); ok {
+			return p
+		}
+	}
+
+	return h.delay
+}
+
+// latencyTracker - скользящее окно задержек успешных запросов.
+type latencyTracker struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	pos     int
+}
+
+// observe - добавление задержки в окно.
+func (t *latencyTracker) observe(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.samples) < latencyWindow {
+		t.samples = append(t.samples, d)
+		return
+	}
+
+	t.samples[t.pos] = d
+	t.pos = (t.pos + 1) % latencyWindow
+}
+
+// percentile - p-й перцентиль задержек, false если наблюдений недостаточно.
+func (t *latencyTracker) percentile(p float64) (time.Duration, bool) {
+	t.mu.Lock()
+	sorted := slices.Clone(t.samples)
+	t.mu.Unlock()
+
+	if len(sorted) < minLatencySamples {
+		return 0, false
+	}
+
+	slices.Sort(sorted)
+
+	idx := int(p / 100 * float64(len(sorted)-1))
+	idx = max(0, min(idx, len(sorted)-1))
+
+	return sorted[idx], true
+}
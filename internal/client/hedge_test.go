@@ -0,0 +1,48 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sunr3d/quorum-grep/internal/config"
+)
+
+func TestHedgePolicy_threshold(t *testing.T) {
+	h := newHedgePolicy(config.HedgeConfig{
+		Enabled:    true,
+		Delay:      "200ms",
+		Percentile: 90,
+		MaxHedges:  1,
+	})
+
+	// наблюдений мало - используется фиксированная задержка
+	for i := range minLatencySamples - 1 {
+		h.latencies.observe(time.Duration(i+1) * time.Millisecond)
+	}
+	assert.Equal(t, 200*time.Millisecond, h.threshold())
+
+	// 1..100 мс, 90-й перцентиль
+	for i := minLatencySamples - 1; i < 100; i++ {
+		h.latencies.observe(time.Duration(i+1) * time.Millisecond)
+	}
+	assert.Equal(t, 90*time.Millisecond, h.threshold())
+}
+
+func TestLatencyTracker_window(t *testing.T) {
+	tracker := &latencyTracker{}
+
+	for range latencyWindow {
+		tracker.observe(time.Second)
+	}
+	// старые значения вытесняются новыми
+	for range latencyWindow {
+		tracker.observe(time.Millisecond)
+	}
+
+	p, ok := tracker.percentile(100)
+	assert.True(t, ok)
+	assert.Equal(t, time.Millisecond, p)
+	assert.Len(t, tracker.samples, latencyWindow)
+}
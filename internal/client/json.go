@@ -0,0 +1,195 @@
+package client
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+	"unicode/utf8"
+)
+
+// jsonFormatter - вывод в формате NDJSON, совместимом с ripgrep --json:
+// события begin, match, context и end для каждого файла с совпадениями
+// и итоговое summary.
+type jsonFormatter struct {
+	w       *bufio.Writer
+	started time.Time
+	total   jsonStats
+}
+
+func newJSONFormatter(w io.Writer) *jsonFormatter {
+	return &jsonFormatter{
+		w:       bufio.NewWriter(w),
+		started: time.Now(),
+	}
+}
+
+type jsonMessage struct {
+	Type string `json:"type"`
+	Data any    `json:"data"`
+}
+
+// jsonData - текст, либо base64 для данных не в UTF-8.
+type jsonData struct {
+	Text  *string `json:"text,omitempty"`
+	Bytes *string `json:"bytes,omitempty"`
+}
+
+type jsonBegin struct {
+	Path jsonData `json:"path"`
+}
+
+type jsonLine struct {
+	Path           jsonData       `json:"path"`
+	Lines          jsonData       `json:"lines"`
+	LineNumber     int64          `json:"line_number"`
+	AbsoluteOffset int64          `json:"absolute_offset"`
+	Submatches     []jsonSubmatch `json:"submatches"`
+}
+
+type jsonSubmatch struct {
+	Match jsonData `json:"match"`
+	Start int      `json:"start"`
+	End   int      `json:"end"`
+}
+
+type jsonEnd struct {
+	Path         jsonData  `json:"path"`
+	BinaryOffset *int64    `json:"binary_offset"`
+	Stats        jsonStats `json:"stats"`
+}
+
+type jsonSummary struct {
+	ElapsedTotal jsonDuration `json:"elapsed_total"`
+	Stats        jsonStats    `json:"stats"`
+}
+
+type jsonStats struct {
+	Elapsed           jsonDuration `json:"elapsed"`
+	Searches          int64        `json:"searches"`
+	SearchesWithMatch int64        `json:"searches_with_match"`
+	BytesSearched     int64        `json:"bytes_searched"`
+	BytesPrinted      int64        `json:"bytes_printed"`
+	MatchedLines      int64        `json:"matched_lines"`
+	Matches           int64        `json:"matches"`
+}
+
+type jsonDuration struct {
+	Secs  int64  `json:"secs"`
+	Nanos int64  `json:"nanos"`
+	Human string `json:"human"`
+}
+
+func newJSONDuration(d time.Duration) jsonDuration {
+	return jsonDuration{
+		Secs:  int64(d / time.Second),
+		Nanos: int64(d % time.Second),
+		Human: fmt.Sprintf("%.6fs", d.Seconds()),
+	}
+}
+
+func newJSONData(b []byte) jsonData {
+	if utf8.Valid(b) {
+		s := string(b)
+		return jsonData{Text: &s}
+	}
+
+	s := base64.StdEncoding.EncodeToString(b)
+	return jsonData{Bytes: &s}
+}
+
+// File - события для одного файла. Строки, выбранные поиском, выводятся
+// как match с границами совпадений, остальные - как context.
+func (p *jsonFormatter) File(res *FileResult) error {
+	stats := jsonStats{
+		Elapsed:       newJSONDuration(res.Elapsed),
+		Searches:      1,
+		BytesSearched: res.BytesSearched(),
+	}
+
+	if len(res.Matches) > 0 {
+		stats.SearchesWithMatch = 1
+		path := newJSONData([]byte(res.DisplayName()))
+
+		if err := p.write(&stats, "begin", jsonBegin{Path: path}); err != nil {
+			return err
+		}
+
+		err := res.Each(func(line OutputLine) error {
+			event := jsonLine{
+				Path:           path,
+				Lines:          newJSONData(append(line.Text[:len(line.Text):len(line.Text)], '\n')),
+				LineNumber:     line.Number,
+				AbsoluteOffset: line.Offset,
+				Submatches:     []jsonSubmatch{},
+			}
+
+			typ := "context"
+			if line.Match {
+				typ = "match"
+				stats.MatchedLines++
+				for _, sm := range line.Submatches {
+					event.Submatches = append(event.Submatches, jsonSubmatch{
+						Match: newJSONData(line.Text[sm[0]:sm[1]]),
+						Start: sm[0],
+						End:   sm[1],
+					})
+				}
+				stats.Matches += int64(max(len(event.Submatches), 1))
+			}
+
+			return p.write(&stats, typ, event)
+		})
+		if err != nil {
+			return err
+		}
+
+		end := jsonEnd{Path: path, Stats: stats}
+		if err := p.write(&stats, "end", end); err != nil {
+			return err
+		}
+	}
+
+	p.total.Searches += stats.Searches
+	p.total.SearchesWithMatch += stats.SearchesWithMatch
+	p.total.BytesSearched += stats.BytesSearched
+	p.total.BytesPrinted += stats.BytesPrinted
+	p.total.MatchedLines += stats.MatchedLines
+	p.total.Matches += stats.Matches
+	p.total.Elapsed = newJSONDuration(time.Duration(p.total.Elapsed.Secs)*time.Second +
+		time.Duration(p.total.Elapsed.Nanos) + res.Elapsed)
+
+	return p.w.Flush()
+}
+
+// Close - итоговое событие summary по всем файлам.
+func (p *jsonFormatter) Close() error {
+	if err := p.write(nil, "summary", jsonSummary{
+		ElapsedTotal: newJSONDuration(time.Since(p.started)),
+		Stats:        p.total,
+	}); err != nil {
+		return err
+	}
+
+	return p.w.Flush()
+}
+
+// write - одно событие в отдельной строке; размер учитывается в stats.
+func (p *jsonFormatter) write(stats *jsonStats, typ string, data any) error {
+	b, err := json.Marshal(jsonMessage{Type: typ, Data: data})
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+	b = append(b, '\n')
+
+	if _, err := p.w.Write(b); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+	if stats != nil {
+		stats.BytesPrinted += int64(len(b))
+	}
+
+	return nil
+}
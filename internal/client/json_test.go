@@ -0,0 +1,64 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sunr3d/quorum-grep/models"
+)
+
+func TestJSONFormatter(t *testing.T) {
+	lines := [][]byte{[]byte("ok"), []byte("foo error"), []byte("mid"), []byte("\xff error")}
+	matches := []models.Match{
+		{Content: lines[1], LineNumber: 2},
+		{Content: lines[2], LineNumber: 3},
+		{Content: lines[3], LineNumber: 4},
+	}
+
+	var buf bytes.Buffer
+	f := newJSONFormatter(&buf)
+	require.NoError(t, f.File(&FileResult{File: "a.log", Lines: lines, Matches: matches, Options: models.GrepOptions{Pattern: "error", Before: 1}}))
+	require.NoError(t, f.File(&FileResult{File: "-", Lines: lines, Options: models.GrepOptions{Pattern: "error"}}))
+	require.NoError(t, f.Close())
+
+	var events []map[string]any
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var ev map[string]any
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &ev))
+		events = append(events, ev)
+	}
+
+	var types []string
+	for _, ev := range events {
+		types = append(types, ev["type"].(string))
+	}
+	// файл без совпадений не дает begin/end
+	assert.Equal(t, []string{"begin", "match", "context", "match", "end", "summary"}, types)
+
+	match := events[1]["data"].(map[string]any)
+	assert.Equal(t, "foo error\n", match["lines"].(map[string]any)["text"])
+	assert.Equal(t, 3.0, match["absolute_offset"])
+	submatch := match["submatches"].([]any)[0].(map[string]any)
+	assert.Equal(t, 4.0, submatch["start"])
+	assert.Equal(t, 9.0, submatch["end"])
+
+	context := events[2]["data"].(map[string]any)
+	assert.Equal(t, 13.0, context["absolute_offset"])
+	assert.Empty(t, context["submatches"])
+
+	binary := events[3]["data"].(map[string]any)
+	assert.Equal(t, "/yBlcnJvcgo=", binary["lines"].(map[string]any)["bytes"])
+	assert.Equal(t, 17.0, binary["absolute_offset"])
+
+	stats := events[5]["data"].(map[string]any)["stats"].(map[string]any)
+	assert.Equal(t, 2.0, stats["searches"])
+	assert.Equal(t, 1.0, stats["searches_with_match"])
+	assert.Equal(t, 2.0, stats["matched_lines"])
+	assert.Equal(t, 2.0, stats["matches"])
+}
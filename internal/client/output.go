@@ -0,0 +1,291 @@
+package client
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/sunr3d/quorum-grep/internal/services/grepsvc"
+	"github.com/sunr3d/quorum-grep/models"
+)
+
+// Форматы вывода для NewFormatter.
+const (
+	FormatText  = "text"
+	FormatJSON  = "json"
+	FormatCSV   = "csv"
+	FormatSARIF = "sarif"
+)
+
+// OutputFormatter - формат вывода результатов поиска.
+// Реализации буферизуют вывод и сбрасывают буфер после каждого файла.
+type OutputFormatter interface {
+	// File - вывод результатов поиска в одном файле.
+	File(res *FileResult) error
+	// Close - завершение вывода: итоги по всем файлам и сброс буфера.
+	Close() error
+}
+
+// FileResult - результаты поиска в одном файле.
+type FileResult struct {
+	File    string
+	Options models.GrepOptions
+	// Matches - найденные строки и строки контекста по возрастанию номера.
+	Matches []models.Match
+	// Lines - все прочитанные строки файла, нужны для смещений.
+	Lines   [][]byte
+	Elapsed time.Duration
+}
+
+// OutputLine - строка результата с данными, которые вычисляются
+// только по запросу формата: смещение и границы совпадений.
+type OutputLine struct {
+	Number int64
+	// Offset - смещение начала строки от начала файла в байтах.
+	Offset int64
+	Text   []byte
+	// Match - строка выбрана поиском, иначе это строка контекста.
+	Match bool
+	// Submatches - границы [start, end) совпадений шаблона в Text.
+	Submatches [][]int
+}
+
+// DisplayName - имя файла для вывода, stdin как в ripgrep.
+func (r *FileResult) DisplayName() string {
+	if r.File == "-" || r.File == "" {
+		return "<stdin>"
+	}
+
+	return r.File
+}
+
+// BytesSearched - объем прочитанных данных.
+func (r *FileResult) BytesSearched() int64 {
+	var n int64
+	for _, line := range r.Lines {
+		n += int64(len(line)) + 1
+	}
+
+	return n
+}
+
+// Each - обход строк результата со смещениями и границами совпадений.
+func (r *FileResult) Each(fn func(OutputLine) error) error {
+	if len(r.Matches) == 0 {
+		return nil
+	}
+
+	lm, err := grepsvc.NewLineMatcher(r.Options)
+	if err != nil {
+		return fmt.Errorf("grepsvc.NewLineMatcher: %w", err)
+	}
+
+	// смещения считаются одним проходом: строки отсортированы по номеру
+	var offset int64
+	nextLine := int64(1)
+	for _, m := range r.Matches {
+		for ; nextLine < m.LineNumber && int(nextLine) <= len(r.Lines); nextLine++ {
+			offset += int64(len(r.Lines[nextLine-1])) + 1
+		}
+
+		line := OutputLine{
+			Number: m.LineNumber,
+			Offset: offset,
+			Text:   m.Content,
+			Match:  lm.Selected(m.Content),
+		}
+		if line.Match {
+			line.Submatches = lm.Submatches(m.Content)
+		}
+
+		if err := fn(line); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// NewFormatter - формат вывода по имени: text, json, csv, sarif.
+// Значение с "{{" считается шаблоном text/template (см. TemplateData).
+func NewFormatter(format string, w io.Writer) (OutputFormatter, error) {
+	switch format {
+	case FormatText, "":
+		return newTextFormatter(w), nil
+	case FormatJSON:
+		return newJSONFormatter(w), nil
+	case FormatCSV:
+		return newCSVFormatter(w), nil
+	case FormatSARIF:
+		return newSARIFFormatter(w), nil
+	}
+
+	if strings.Contains(format, "{{") {
+		return newTemplateFormatter(format, w)
+	}
+
+	return nil, fmt.Errorf("неизвестный формат %q, ожидается %s, %s, %s, %s или шаблон",
+		format, FormatText, FormatJSON, FormatCSV, FormatSARIF)
+}
+
+// textFormatter - вывод как у grep.
+type textFormatter struct {
+	w *bufio.Writer
+}
+
+func newTextFormatter(w io.Writer) *textFormatter {
+	return &textFormatter{w: bufio.NewWriter(w)}
+}
+
+func (f *textFormatter) File(res *FileResult) error {
+	if res.Options.Count {
+		fmt.Fprintln(f.w, len(res.Matches))
+		return f.w.Flush()
+	}
+
+	for _, m := range res.Matches {
+		if res.Options.LineNum {
+			f.w.WriteString(strconv.FormatInt(m.LineNumber, 10))
+			f.w.WriteByte(':')
+		}
+		f.w.Write(m.Content)
+		f.w.WriteByte('\n')
+	}
+
+	// ошибки записи bufio.Writer запоминает и возвращает из Flush
+	return f.w.Flush()
+}
+
+func (f *textFormatter) Close() error {
+	return f.w.Flush()
+}
+
+// csvFormatter - CSV с заголовком: file,line,offset,type,text;
+// с -c - file,count.
+type csvFormatter struct {
+	w      *csv.Writer
+	header bool
+}
+
+func newCSVFormatter(w io.Writer) *csvFormatter {
+	return &csvFormatter{w: csv.NewWriter(w)}
+}
+
+func (f *csvFormatter) File(res *FileResult) error {
+	if !f.header {
+		f.header = true
+		header := []string{"file", "line", "offset", "type", "text"}
+		if res.Options.Count {
+			header = []string{"file", "count"}
+		}
+		if err := f.w.Write(header); err != nil {
+			return fmt.Errorf("csv.Write: %w", err)
+		}
+	}
+
+	if res.Options.Count {
+		if err := f.w.Write([]string{res.DisplayName(), strconv.Itoa(len(res.Matches))}); err != nil {
+			return fmt.Errorf("csv.Write: %w", err)
+		}
+	} else {
+		err := res.Each(func(line OutputLine) error {
+			typ := "context"
+			if line.Match {
+				typ = "match"
+			}
+			return f.w.Write([]string{
+				res.DisplayName(),
+				strconv.FormatInt(line.Number, 10),
+				strconv.FormatInt(line.Offset, 10),
+				typ,
+				string(line.Text),
+			})
+		})
+		if err != nil {
+			return fmt.Errorf("csv.Write: %w", err)
+		}
+	}
+
+	f.w.Flush()
+	return f.w.Error()
+}
+
+func (f *csvFormatter) Close() error {
+	f.w.Flush()
+	return f.w.Error()
+}
+
+// TemplateData - данные для шаблона --format. Шаблон выполняется для
+// каждой строки результата, а с -c - один раз на файл с заполненным Count.
+type TemplateData struct {
+	File   string
+	Line   int64
+	Offset int64
+	Text   string
+	// Match - строка выбрана поиском, иначе это строка контекста.
+	Match bool
+	// Submatches - тексты совпадений шаблона в строке.
+	Submatches []string
+	Count      int
+}
+
+// templateFormatter - вывод по шаблону text/template, после каждой
+// записи добавляется перевод строки.
+type templateFormatter struct {
+	w    *bufio.Writer
+	tmpl *template.Template
+}
+
+func newTemplateFormatter(text string, w io.Writer) (*templateFormatter, error) {
+	tmpl, err := template.New("format").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("template.Parse: %w", err)
+	}
+
+	return &templateFormatter{w: bufio.NewWriter(w), tmpl: tmpl}, nil
+}
+
+func (f *templateFormatter) File(res *FileResult) error {
+	if res.Options.Count {
+		if err := f.execute(TemplateData{File: res.DisplayName(), Count: len(res.Matches)}); err != nil {
+			return err
+		}
+		return f.w.Flush()
+	}
+
+	err := res.Each(func(line OutputLine) error {
+		data := TemplateData{
+			File:   res.DisplayName(),
+			Line:   line.Number,
+			Offset: line.Offset,
+			Text:   string(line.Text),
+			Match:  line.Match,
+		}
+		for _, sm := range line.Submatches {
+			data.Submatches = append(data.Submatches, string(line.Text[sm[0]:sm[1]]))
+		}
+		return f.execute(data)
+	})
+	if err != nil {
+		return err
+	}
+
+	return f.w.Flush()
+}
+
+func (f *templateFormatter) execute(data TemplateData) error {
+	if err := f.tmpl.Execute(f.w, data); err != nil {
+		return fmt.Errorf("template.Execute: %w", err)
+	}
+
+	return f.w.WriteByte('\n')
+}
+
+func (f *templateFormatter) Close() error {
+	return f.w.Flush()
+}
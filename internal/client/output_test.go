@@ -0,0 +1,135 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sunr3d/quorum-grep/models"
+)
+
+func TestFormatters(t *testing.T) {
+	lines := [][]byte{[]byte("ok"), []byte("foo error"), []byte("mid"), []byte("a,\"error\"")}
+	matches := []models.Match{
+		{Content: lines[1], LineNumber: 2},
+		{Content: lines[2], LineNumber: 3},
+		{Content: lines[3], LineNumber: 4},
+	}
+	contextOpts := models.GrepOptions{Pattern: "error", Before: 1, LineNum: true}
+
+	tests := []struct {
+		name     string
+		format   string
+		opts     models.GrepOptions
+		expected string
+	}{
+		{
+			name:     "текст с номерами строк",
+			format:   FormatText,
+			opts:     contextOpts,
+			expected: "2:foo error\n3:mid\n4:a,\"error\"\n",
+		},
+		{
+			name:     "текст с -c",
+			format:   FormatText,
+			opts:     models.GrepOptions{Pattern: "error", Count: true},
+			expected: "3\n",
+		},
+		{
+			name:   "csv",
+			format: FormatCSV,
+			opts:   contextOpts,
+			expected: "file,line,offset,type,text\n" +
+				"a.log,2,3,match,foo error\n" +
+				"a.log,3,13,context,mid\n" +
+				"a.log,4,17,match,\"a,\"\"error\"\"\"\n",
+		},
+		{
+			name:     "csv с -c",
+			format:   FormatCSV,
+			opts:     models.GrepOptions{Pattern: "error", Count: true},
+			expected: "file,count\na.log,3\n",
+		},
+		{
+			name:     "шаблон",
+			format:   "{{.File}}:{{.Line}}:{{.Offset}}: {{.Text}}{{if .Match}} {{.Submatches}}{{end}}",
+			opts:     contextOpts,
+			expected: "a.log:2:3: foo error [error]\na.log:3:13: mid\na.log:4:17: a,\"error\" [error]\n",
+		},
+		{
+			name:     "шаблон с -c",
+			format:   "{{.File}} {{.Count}}",
+			opts:     models.GrepOptions{Pattern: "error", Count: true},
+			expected: "a.log 3\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			f, err := NewFormatter(tt.format, &buf)
+			require.NoError(t, err)
+
+			require.NoError(t, f.File(&FileResult{File: "a.log", Lines: lines, Matches: matches, Options: tt.opts}))
+			// вывод сбрасывается после каждого файла, до Close
+			assert.Equal(t, tt.expected, buf.String())
+			require.NoError(t, f.Close())
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
+
+func TestSARIFFormatter(t *testing.T) {
+	lines := [][]byte{[]byte("ok"), []byte("ёж error"), []byte("mid")}
+	matches := []models.Match{
+		{Content: lines[1], LineNumber: 2},
+		{Content: lines[2], LineNumber: 3},
+	}
+
+	var buf bytes.Buffer
+	f, err := NewFormatter(FormatSARIF, &buf)
+	require.NoError(t, err)
+	require.NoError(t, f.File(&FileResult{File: "a.log", Lines: lines, Matches: matches, Options: models.GrepOptions{Pattern: "error", Before: 1}}))
+	assert.Empty(t, buf.String(), "отчет пишется целиком в Close")
+	require.NoError(t, f.Close())
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+	assert.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+	// строка контекста не попадает в отчет
+	require.Len(t, log.Runs[0].Results, 1)
+
+	loc := log.Runs[0].Results[0].Locations[0].PhysicalLocation
+	assert.Equal(t, "a.log", loc.ArtifactLocation.URI)
+	assert.Equal(t, int64(2), loc.Region.StartLine)
+	assert.Equal(t, 4, loc.Region.StartColumn)
+	assert.Equal(t, 9, loc.Region.EndColumn)
+}
+
+func TestNewFormatter(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		wantErr bool
+	}{
+		{name: "по умолчанию", format: ""},
+		{name: "шаблон", format: "{{.Text}}"},
+		{name: "неизвестный формат", format: "xml", wantErr: true},
+		{name: "ошибка в шаблоне", format: "{{.Text", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewFormatter(tt.format, &bytes.Buffer{})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/sunr3d/quorum-grep/internal/config"
+	"github.com/sunr3d/quorum-grep/internal/discovery"
+)
+
+// Политики кворума (QUORUM.POLICY).
+const (
+	QuorumMajority = "majority"
+	QuorumAll      = "all"
+	QuorumCount    = "count"
+	QuorumWeighted = "weighted"
+)
+
+// quorumPolicy - сколько голосов нужно для результата. Голос - каждый
+// успешно обработанный чанк, а для weighted - вес сервера, обработавшего
+// чанк, за каждый такой чанк. Чанков столько же, сколько серверов,
+// поэтому кворум считается от текущего списка серверов.
+type quorumPolicy struct {
+	policy  string
+	count   int
+	weights map[string]int
+}
+
+// newQuorumPolicy - политика из cfg с проверкой. Для DISCOVERY.MODE static
+// число и веса серверов сверяются с SERVER_LIST.
+func newQuorumPolicy(cfg config.QuorumConfig, disc config.DiscoveryConfig, static []string) (*quorumPolicy, error) {
+	isStatic := disc.Mode == discovery.ModeStatic || disc.Mode == ""
+	p := &quorumPolicy{policy: cfg.Policy}
+
+	switch cfg.Policy {
+	case QuorumMajority, QuorumAll, "":
+	case QuorumCount:
+		if cfg.Count < 1 {
+			return nil, fmt.Errorf("QUORUM.COUNT должен быть не меньше 1, указан %d", cfg.Count)
+		}
+		if isStatic && cfg.Count > len(static) {
+			return nil, fmt.Errorf("QUORUM.COUNT %d больше числа серверов %d", cfg.Count, len(static))
+		}
+		p.count = cfg.Count
+	case QuorumWeighted:
+		if len(cfg.Weights) == 0 {
+			return nil, errors.New("для QUORUM.POLICY weighted нужны QUORUM.WEIGHTS")
+		}
+		p.weights = make(map[string]int, len(cfg.Weights))
+		for _, w := range cfg.Weights {
+			if w.Weight < 1 {
+				return nil, fmt.Errorf("вес сервера %s должен быть не меньше 1, указан %d", w.Server, w.Weight)
+			}
+			if _, ok := p.weights[w.Server]; ok {
+				return nil, fmt.Errorf("вес сервера %s указан дважды", w.Server)
+			}
+			if isStatic && !slices.Contains(static, w.Server) {
+				return nil, fmt.Errorf("сервера %s с весом нет в SERVER_LIST", w.Server)
+			}
+			p.weights[w.Server] = w.Weight
+		}
+	default:
+		return nil, fmt.Errorf("неизвестная QUORUM.POLICY %q, ожидается %s, %s, %s или %s",
+			cfg.Policy, QuorumMajority, QuorumAll, QuorumCount, QuorumWeighted)
+	}
+
+	return p, nil
+}
+
+// ApplyQuorumFlag - переопределение QUORUM значением флага --quorum:
+// majority, all, weighted (веса из конфига) или число чанков.
+func ApplyQuorumFlag(cfg *config.QuorumConfig, value string) error {
+	switch value {
+	case QuorumMajority, QuorumAll, QuorumWeighted:
+		cfg.Policy = value
+		return nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("ожидается %s, %s, %s или число, указано %q", QuorumMajority, QuorumAll, QuorumWeighted, value)
+	}
+	cfg.Policy = QuorumCount
+	cfg.Count = n
+
+	return nil
+}
+
+// required - сколько голосов нужно при текущих серверах members. Нужен
+// хотя бы один голос: без серверов (например, все на карантине) пустой
+// результат не должен считаться кворумом.
+func (p *quorumPolicy) required(members []string) int {
+	switch p.policy {
+	case QuorumAll:
+		return max(len(members), 1)
+	case QuorumCount:
+		return p.count
+	case QuorumWeighted:
+		return p.votes(members)/2 + 1
+	default:
+		return len(members)/2 + 1
+	}
+}
+
+// chunkVotes - голоса чанков, успешно обработанных серверами; чанки,
+// обработанные локально (FALLBACK), не голосуют.
+func (p *quorumPolicy) chunkVotes(outcomes []ChunkOutcome) int {
+	if p.policy != QuorumWeighted {
+		votes := 0
+		for _, o := range outcomes {
+			if o.Err == nil && !o.Local {
+				votes++
+			}
+		}
+		return votes
+	}
+
+	// каждый чанк со своим весом: сервер, ответивший на один чанк,
+	// не закрывает своим весом чанки, которые не обработаны
+	votes := 0
+	for _, o := range outcomes {
+		if o.Err == nil && !o.Local {
+			votes += p.weight(o.Server)
+		}
+	}
+
+	return votes
+}
+
+// weight - голос сервера.
+func (p *quorumPolicy) weight(server string) int {
+	if w, ok := p.weights[server]; ok {
+		return w
+	}
+
+	return 1
+}
+
+// votes - сумма голосов servers.
+func (p *quorumPolicy) votes(servers []string) int {
+	total := 0
+	for _, server := range servers {
+		total += p.weight(server)
+	}
+
+	return total
+}
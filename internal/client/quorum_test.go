@@ -0,0 +1,169 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sunr3d/quorum-grep/internal/config"
+	"github.com/sunr3d/quorum-grep/internal/discovery"
+)
+
+func TestQuorumPolicy(t *testing.T) {
+	servers := []string{"a:1", "b:1", "c:1", "d:1", "e:1"}
+	weights := []config.ServerWeight{{Server: "a:1", Weight: 3}, {Server: "b:1", Weight: 2}}
+
+	tests := []struct {
+		name     string
+		cfg      config.QuorumConfig
+		members  []string
+		healthy  []string
+		expected int
+		votes    int
+	}{
+		{name: "большинство по умолчанию", members: servers, healthy: servers[:3], expected: 3, votes: 3},
+		{name: "большинство из четырех", cfg: config.QuorumConfig{Policy: QuorumMajority}, members: servers[:4], expected: 3},
+		{name: "все", cfg: config.QuorumConfig{Policy: QuorumAll}, members: servers, expected: 5},
+		{name: "все без серверов", cfg: config.QuorumConfig{Policy: QuorumAll}, members: nil, expected: 1},
+		{name: "большинство без серверов", members: nil, expected: 1},
+		{name: "явное число", cfg: config.QuorumConfig{Policy: QuorumCount, Count: 2}, members: servers, expected: 2},
+		{
+			// голосов 3+2+1+1+1 = 8, нужно больше половины
+			name:     "веса",
+			cfg:      config.QuorumConfig{Policy: QuorumWeighted, Weights: weights},
+			members:  servers,
+			healthy:  []string{"a:1", "b:1"},
+			expected: 5,
+			votes:    5,
+		},
+		{
+			name:     "веса без тяжелого сервера",
+			cfg:      config.QuorumConfig{Policy: QuorumWeighted, Weights: weights},
+			members:  servers,
+			healthy:  []string{"b:1", "c:1", "d:1"},
+			expected: 5,
+			votes:    4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := newQuorumPolicy(tt.cfg, config.DiscoveryConfig{}, servers)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected, p.required(tt.members))
+			if tt.healthy != nil {
+				assert.Equal(t, tt.votes, p.votes(tt.healthy))
+			}
+		})
+	}
+}
+
+func TestChunkVotes(t *testing.T) {
+	outcomes := []ChunkOutcome{
+		{Index: 0, Server: "a:1"},
+		{Index: 1, Server: "a:1"},
+		{Index: 2, Server: "c:1"},
+		{Index: 3, Server: "b:1", Err: assert.AnError},
+	}
+	weights := []config.ServerWeight{{Server: "a:1", Weight: 3}, {Server: "b:1", Weight: 2}}
+
+	majority, err := newQuorumPolicy(config.QuorumConfig{}, config.DiscoveryConfig{}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 3, majority.chunkVotes(outcomes))
+
+	// вес сервера за каждый обработанный им чанк: 3+3+1
+	weighted, err := newQuorumPolicy(config.QuorumConfig{Policy: QuorumWeighted, Weights: weights}, config.DiscoveryConfig{Mode: discovery.ModeFile}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 7, weighted.chunkVotes(outcomes))
+
+	// тяжелый сервер ответил на один чанк, остальные чанки не обработаны:
+	// голосов 3 из 8, кворум 5 не достигнут
+	members := []string{"a:1", "b:1", "c:1", "d:1", "e:1"}
+	heavy := []ChunkOutcome{
+		{Index: 0, Server: "a:1"},
+		{Index: 1, Server: "b:1", Err: assert.AnError},
+		{Index: 2, Server: "c:1", Err: assert.AnError},
+		{Index: 3, Server: "d:1", Err: assert.AnError},
+		{Index: 4, Server: "e:1", Err: assert.AnError},
+	}
+	assert.Less(t, weighted.chunkVotes(heavy), weighted.required(members))
+}
+
+func TestNewQuorumPolicyErrors(t *testing.T) {
+	servers := []string{"a:1", "b:1", "c:1"}
+	dns := config.DiscoveryConfig{Mode: discovery.ModeDNS}
+
+	tests := []struct {
+		name    string
+		cfg     config.QuorumConfig
+		disc    config.DiscoveryConfig
+		wantErr bool
+	}{
+		{name: "неизвестная политика", cfg: config.QuorumConfig{Policy: "most"}, wantErr: true},
+		{name: "число 0", cfg: config.QuorumConfig{Policy: QuorumCount}, wantErr: true},
+		{name: "число больше серверов", cfg: config.QuorumConfig{Policy: QuorumCount, Count: 4}, wantErr: true},
+		{name: "число больше серверов при dns", cfg: config.QuorumConfig{Policy: QuorumCount, Count: 4}, disc: dns},
+		{name: "веса не указаны", cfg: config.QuorumConfig{Policy: QuorumWeighted}, wantErr: true},
+		{
+			name:    "нулевой вес",
+			cfg:     config.QuorumConfig{Policy: QuorumWeighted, Weights: []config.ServerWeight{{Server: "a:1"}}},
+			wantErr: true,
+		},
+		{
+			name: "вес дважды",
+			cfg: config.QuorumConfig{Policy: QuorumWeighted, Weights: []config.ServerWeight{
+				{Server: "a:1", Weight: 2}, {Server: "a:1", Weight: 3},
+			}},
+			wantErr: true,
+		},
+		{
+			name:    "вес сервера не из списка",
+			cfg:     config.QuorumConfig{Policy: QuorumWeighted, Weights: []config.ServerWeight{{Server: "x:1", Weight: 2}}},
+			wantErr: true,
+		},
+		{
+			name: "вес сервера не из списка при dns",
+			cfg:  config.QuorumConfig{Policy: QuorumWeighted, Weights: []config.ServerWeight{{Server: "x:1", Weight: 2}}},
+			disc: dns,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newQuorumPolicy(tt.cfg, tt.disc, servers)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestApplyQuorumFlag(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected config.QuorumConfig
+		wantErr  bool
+	}{
+		{name: "все", value: "all", expected: config.QuorumConfig{Policy: QuorumAll, Count: 1}},
+		{name: "число", value: "2", expected: config.QuorumConfig{Policy: QuorumCount, Count: 2}},
+		{name: "некорректное значение", value: "half", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.QuorumConfig{Policy: QuorumMajority, Count: 1}
+			err := ApplyQuorumFlag(&cfg, tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, cfg)
+		})
+	}
+}
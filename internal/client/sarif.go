@@ -0,0 +1,143 @@
+package client
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifRuleID  = "pattern-match"
+)
+
+// sarifFormatter - отчет SARIF 2.1.0 для загрузки в системы анализа кода:
+// каждая найденная строка - результат с позицией совпадения. Строки
+// контекста не выводятся. Отчет один на весь запуск и пишется в Close.
+type sarifFormatter struct {
+	w       *bufio.Writer
+	results []sarifResult
+}
+
+func newSARIFFormatter(w io.Writer) *sarifFormatter {
+	return &sarifFormatter{w: bufio.NewWriter(w)}
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool       sarifTool     `json:"tool"`
+	ColumnKind string        `json:"columnKind"`
+	Results    []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifact `json:"artifactLocation"`
+	Region           sarifRegion   `json:"region"`
+}
+
+type sarifArtifact struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int64        `json:"startLine"`
+	StartColumn int          `json:"startColumn,omitempty"`
+	EndColumn   int          `json:"endColumn,omitempty"`
+	Snippet     sarifMessage `json:"snippet"`
+}
+
+func (f *sarifFormatter) File(res *FileResult) error {
+	uri := res.DisplayName()
+
+	return res.Each(func(line OutputLine) error {
+		if !line.Match {
+			return nil
+		}
+
+		text := string(line.Text)
+		region := sarifRegion{StartLine: line.Number, Snippet: sarifMessage{Text: text}}
+		// колонки в символах с 1, отмечается первое совпадение в строке
+		if len(line.Submatches) > 0 {
+			sm := line.Submatches[0]
+			region.StartColumn = utf8.RuneCount(line.Text[:sm[0]]) + 1
+			region.EndColumn = utf8.RuneCount(line.Text[:sm[1]]) + 1
+		}
+
+		f.results = append(f.results, sarifResult{
+			RuleID:  sarifRuleID,
+			Level:   "note",
+			Message: sarifMessage{Text: text},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifact{URI: uri},
+				Region:           region,
+			}}},
+		})
+
+		return nil
+	})
+}
+
+func (f *sarifFormatter) Close() error {
+	results := f.results
+	if results == nil {
+		results = []sarifResult{}
+	}
+
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:  "mygrep",
+				Rules: []sarifRule{{ID: sarifRuleID, ShortDescription: sarifMessage{Text: "Строка совпала с шаблоном поиска."}}},
+			}},
+			ColumnKind: "unicodeCodePoints",
+			Results:    results,
+		}},
+	}
+
+	enc := json.NewEncoder(f.w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(log); err != nil {
+		return fmt.Errorf("json.Encode: %w", err)
+	}
+
+	return f.w.Flush()
+}
@@ -0,0 +1,292 @@
+package client
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Stats - статистика работы клиента с момента создания.
+type Stats struct {
+	// Files - статистика по файлам в порядке обработки.
+	Files []FileStats
+	// Chunks - исход каждого чанка.
+	Chunks []ChunkOutcome
+	// Servers - статистика попыток по серверам.
+	Servers map[string]ServerStats
+
+	// Retries - сколько раз чанк повторно отправлен после ошибки.
+	Retries int64
+	// RateLimitWaits - сколько раз чанк ждал RetryInfo, когда лимит исчерпан везде.
+	RateLimitWaits int64
+	// Hedges - сколько дублирующих запросов отправлено.
+	Hedges int64
+	// HedgeWins - для скольких чанков первым ответил дублирующий запрос.
+	HedgeWins int64
+	// SpotChecks - сколько ответов проверено сверкой с другим сервером
+	// или локально (VERIFY).
+	SpotChecks int64
+	// Mismatches - сколько проверенных ответов не совпало с эталоном.
+	Mismatches int64
+	// Reputation - репутация серверов, если включена проверка.
+	Reputation map[string]ServerReputation
+
+	// Wall - время с создания клиента.
+	Wall time.Duration
+}
+
+// FileStats - статистика поиска в одном файле.
+type FileStats struct {
+	File      string
+	BytesRead int64
+	LinesRead int64
+	// ChunksOK - сколько чанков обработано успешно,
+	// из них ChunksLocal - локально (FALLBACK).
+	ChunksOK    int
+	ChunksLocal int
+	Chunks      int
+	// Quorum - сколько голосов успешных чанков нужно для результата
+	// (для QUORUM.POLICY weighted - сумма весов серверов).
+	Quorum        int
+	QuorumReached bool
+	Matches       int
+	Duration      time.Duration
+	Err           error
+}
+
+// ChunkOutcome - исход обработки одного чанка.
+type ChunkOutcome struct {
+	File  string
+	Index int
+	// Server - сервер, чей ответ принят, либо последний, вернувший ошибку.
+	Server   string
+	Attempts int
+	// Hedged - первым ответил дублирующий запрос.
+	Hedged bool
+	// CrossChecked - сервер, с ответом которого сверен принятый ответ.
+	CrossChecked string
+	// SpotChecked - чанк обработан локально для проверки, Mismatch - принятый
+	// ответ не совпал с эталоном и заменен им.
+	SpotChecked bool
+	Mismatch    bool
+	// Local - чанк обработан локально после ошибок серверов (FALLBACK).
+	Local bool
+	Err   error
+}
+
+// ServerStats - попытки обработки чанков на одном сервере.
+type ServerStats struct {
+	Chunks   int64
+	Failures int64
+	// Latency - суммарное время успешных попыток.
+	Latency    time.Duration
+	MaxLatency time.Duration
+}
+
+// AvgLatency - среднее время успешной попытки.
+func (s ServerStats) AvgLatency() time.Duration {
+	if s.Chunks == 0 {
+		return 0
+	}
+
+	return s.Latency / time.Duration(s.Chunks)
+}
+
+// Matches - совпадения во всех файлах.
+func (s Stats) Matches() int {
+	total := 0
+	for _, f := range s.Files {
+		total += f.Matches
+	}
+
+	return total
+}
+
+// WriteReport - отчет для --stats в читаемом виде.
+func (s Stats) WriteReport(w io.Writer) error {
+	var bytesRead, linesRead int64
+	chunks, chunksOK, chunksLocal := 0, 0, 0
+	for _, f := range s.Files {
+		bytesRead += f.BytesRead
+		linesRead += f.LinesRead
+		chunks += f.Chunks
+		chunksOK += f.ChunksOK
+		chunksLocal += f.ChunksLocal
+	}
+
+	r := &reportWriter{w: w}
+	r.printf("статистика:\n")
+	r.printf("  время:       %s\n", s.Wall.Round(time.Millisecond))
+	r.printf("  прочитано:   %d байт, %d строк, файлов %d\n", bytesRead, linesRead, len(s.Files))
+	r.printf("  чанков:      %d, успешно %d, локально %d\n", chunks, chunksOK, chunksLocal)
+	r.printf("  повторов:    %d, ожиданий лимита %d, дублей %d (первыми ответили %d)\n",
+		s.Retries, s.RateLimitWaits, s.Hedges, s.HedgeWins)
+	if s.Reputation != nil {
+		r.printf("  проверок:    %d, расхождений %d\n", s.SpotChecks, s.Mismatches)
+	}
+	r.printf("  совпадений:  %d\n", s.Matches())
+
+	for _, f := range s.Files {
+		quorum := "достигнут"
+		if !f.QuorumReached {
+			quorum = "не достигнут"
+		}
+		local := ""
+		if f.ChunksLocal > 0 {
+			local = fmt.Sprintf(" (локально %d)", f.ChunksLocal)
+		}
+		r.printf("  файл %s: чанков %d/%d%s, кворум %d %s, совпадений %d, %s\n",
+			f.File, f.ChunksOK, f.Chunks, local, f.Quorum, quorum, f.Matches, f.Duration.Round(time.Microsecond))
+		if f.Err != nil {
+			r.printf("    ошибка: %v\n", f.Err)
+		}
+	}
+
+	for _, server := range slices.Sorted(maps.Keys(s.Servers)) {
+		st := s.Servers[server]
+		r.printf("  сервер %s: чанков %d, ошибок %d, задержка ср. %s, макс. %s\n",
+			server, st.Chunks, st.Failures, st.AvgLatency().Round(time.Microsecond), st.MaxLatency.Round(time.Microsecond))
+	}
+
+	for _, server := range slices.Sorted(maps.Keys(s.Reputation)) {
+		rep := s.Reputation[server]
+		r.printf("  репутация %s: проверок %d, расхождений %d", server, rep.Checks, rep.Disagreements)
+		if time.Now().Before(rep.QuarantinedUntil) {
+			r.printf(", карантин до %s", rep.QuarantinedUntil.Format(time.DateTime))
+		}
+		r.printf("\n")
+	}
+
+	for _, ch := range s.Chunks {
+		check := ""
+		if ch.CrossChecked != "" {
+			check = ", сверен с " + ch.CrossChecked
+		}
+		if ch.SpotChecked {
+			check += ", проверен локально"
+		}
+		if ch.Mismatch {
+			check += ", расхождение"
+		}
+
+		switch {
+		case ch.Local:
+			r.printf("  чанк %s#%d: локально, попыток на серверах %d\n", ch.File, ch.Index, ch.Attempts)
+		case ch.Err != nil:
+			r.printf("  чанк %s#%d: ошибка после %d попыток: %v\n", ch.File, ch.Index, ch.Attempts, ch.Err)
+		case ch.Hedged:
+			r.printf("  чанк %s#%d: %s (дубль), попыток %d%s\n", ch.File, ch.Index, ch.Server, ch.Attempts, check)
+		default:
+			r.printf("  чанк %s#%d: %s, попыток %d%s\n", ch.File, ch.Index, ch.Server, ch.Attempts, check)
+		}
+	}
+
+	return r.err
+}
+
+// reportWriter - запись отчета с сохранением первой ошибки.
+type reportWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (r *reportWriter) printf(format string, args ...any) {
+	if r.err == nil {
+		_, r.err = fmt.Fprintf(r.w, format, args...)
+	}
+}
+
+// counters - счетчики, обновляемые из горутин отправки чанков.
+type counters struct {
+	started time.Time
+
+	retries        atomic.Int64
+	rateLimitWaits atomic.Int64
+	hedges         atomic.Int64
+	hedgeWins      atomic.Int64
+	spotChecks     atomic.Int64
+	mismatches     atomic.Int64
+
+	mu sync.Mutex
+	// noHistory - не хранить Files и Chunks (DisableHistory).
+	noHistory bool
+	files     []FileStats
+	chunks    []ChunkOutcome
+	servers   map[string]ServerStats
+}
+
+func newCounters() *counters {
+	return &counters{
+		started: time.Now(),
+		servers: make(map[string]ServerStats),
+	}
+}
+
+// attempt - учет одной завершенной попытки на сервере.
+func (c *counters) attempt(server string, took time.Duration, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	st := c.servers[server]
+	if err != nil {
+		st.Failures++
+	} else {
+		st.Chunks++
+		st.Latency += took
+		st.MaxLatency = max(st.MaxLatency, took)
+	}
+	c.servers[server] = st
+}
+
+// file - учет поиска в файле и исходов его чанков.
+func (c *counters) file(f FileStats, chunks []ChunkOutcome) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.noHistory {
+		return
+	}
+	c.files = append(c.files, f)
+	c.chunks = append(c.chunks, chunks...)
+}
+
+// DisableHistory - не хранить статистику по файлам и чанкам (Stats.Files,
+// Stats.Chunks), чтобы она не росла в долгоживущем процессе. Счетчики
+// повторов и статистика серверов продолжают собираться.
+func (c *Client) DisableHistory() {
+	c.counters.mu.Lock()
+	defer c.counters.mu.Unlock()
+
+	c.counters.noHistory = true
+	c.counters.files = nil
+	c.counters.chunks = nil
+}
+
+// Stats - снимок статистики клиента.
+func (c *Client) Stats() Stats {
+	c.counters.mu.Lock()
+	defer c.counters.mu.Unlock()
+
+	var rep map[string]ServerReputation
+	if c.verify != nil {
+		rep = c.verify.rep.snapshot()
+	}
+
+	return Stats{
+		Files:          slices.Clone(c.counters.files),
+		Chunks:         slices.Clone(c.counters.chunks),
+		Servers:        maps.Clone(c.counters.servers),
+		Retries:        c.counters.retries.Load(),
+		RateLimitWaits: c.counters.rateLimitWaits.Load(),
+		Hedges:         c.counters.hedges.Load(),
+		HedgeWins:      c.counters.hedgeWins.Load(),
+		SpotChecks:     c.counters.spotChecks.Load(),
+		Mismatches:     c.counters.mismatches.Load(),
+		Reputation:     rep,
+		Wall:           time.Since(c.counters.started),
+	}
+}
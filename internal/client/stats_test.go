@@ -0,0 +1,54 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCounters(t *testing.T) {
+	c := &Client{counters: newCounters()}
+
+	c.counters.attempt("a:1", 10*time.Millisecond, nil)
+	c.counters.attempt("a:1", 30*time.Millisecond, nil)
+	c.counters.attempt("b:2", time.Millisecond, errors.New("unavailable"))
+	c.counters.retries.Add(1)
+	c.counters.file(
+		FileStats{File: "x.log", BytesRead: 10, LinesRead: 2, Chunks: 2, ChunksOK: 2, Quorum: 2, QuorumReached: true, Matches: 3},
+		[]ChunkOutcome{
+			{File: "x.log", Index: 0, Server: "a:1", Attempts: 2},
+			{File: "x.log", Index: 1, Server: "a:1", Attempts: 1},
+		},
+	)
+	c.counters.file(
+		FileStats{File: "y.log", Chunks: 1, ChunksOK: 1, ChunksLocal: 1, Quorum: 1, Matches: 1},
+		[]ChunkOutcome{{File: "y.log", Index: 0, Server: "b:2", Attempts: 1, Local: true}},
+	)
+
+	stats := c.Stats()
+	assert.Equal(t, 4, stats.Matches())
+	assert.Equal(t, int64(1), stats.Retries)
+	assert.Len(t, stats.Chunks, 3)
+	assert.Equal(t, ServerStats{Chunks: 2, Latency: 40 * time.Millisecond, MaxLatency: 30 * time.Millisecond}, stats.Servers["a:1"])
+	assert.Equal(t, 20*time.Millisecond, stats.Servers["a:1"].AvgLatency())
+	assert.Equal(t, int64(1), stats.Servers["b:2"].Failures)
+
+	// снимок не меняется вместе со счетчиками
+	c.counters.attempt("a:1", time.Millisecond, nil)
+	assert.Equal(t, int64(2), stats.Servers["a:1"].Chunks)
+
+	var buf bytes.Buffer
+	require.NoError(t, stats.WriteReport(&buf))
+	report := buf.String()
+	assert.Contains(t, report, "прочитано:   10 байт, 2 строк, файлов 2")
+	assert.Contains(t, report, "файл x.log: чанков 2/2, кворум 2 достигнут, совпадений 3")
+	assert.Contains(t, report, "чанков:      3, успешно 3, локально 1")
+	assert.Contains(t, report, "файл y.log: чанков 1/1 (локально 1), кворум 1 не достигнут")
+	assert.Contains(t, report, "чанк y.log#0: локально, попыток на серверах 1")
+	assert.Contains(t, report, "сервер b:2: чанков 0, ошибок 1")
+	assert.Contains(t, report, "чанк x.log#0: a:1, попыток 2")
+}
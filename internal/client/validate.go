@@ -0,0 +1,34 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sunr3d/quorum-grep/internal/services/grepsvc"
+	"github.com/sunr3d/quorum-grep/models"
+)
+
+// ValidateOptions - проверка опций поиска до чтения входных данных.
+// Шаблон компилируется той же реализацией, что и на серверах, поэтому
+// некорректный шаблон не уходит в сеть.
+func ValidateOptions(opts models.GrepOptions) error {
+	if opts.After < 0 || opts.Before < 0 || opts.Around < 0 {
+		return fmt.Errorf("%w: размер контекста не может быть отрицательным", models.ErrUnsupportedOptions)
+	}
+
+	// серверы считают строки контекста вместе с совпадениями
+	if opts.Count && (opts.After > 0 || opts.Before > 0 || opts.Around > 0) {
+		return fmt.Errorf("%w: -c нельзя использовать с -A, -B, -C", models.ErrUnsupportedOptions)
+	}
+
+	// серверы проверяют шаблон построчно, несколько шаблонов через '\n' не поддерживаются
+	if strings.Contains(opts.Pattern, "\n") {
+		return fmt.Errorf("%w: шаблон не может содержать перевод строки", models.ErrUnsupportedOptions)
+	}
+
+	if err := grepsvc.ValidatePattern(opts); err != nil {
+		return err
+	}
+
+	return nil
+}
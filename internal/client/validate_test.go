@@ -0,0 +1,48 @@
+package client
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sunr3d/quorum-grep/models"
+)
+
+func TestValidateOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts models.GrepOptions
+		is   error
+		pos  int
+	}{
+		{name: "корректный шаблон", opts: models.GrepOptions{Pattern: `error \d+`, After: 2}},
+		{name: "фиксированная строка со скобкой", opts: models.GrepOptions{Pattern: "[bad", Fixed: true}},
+		{name: "незакрытая скобка", opts: models.GrepOptions{Pattern: "[bad"}, is: models.ErrInvalidPattern, pos: 1},
+		{name: "вложенный повтор", opts: models.GrepOptions{Pattern: "ab**"}, is: models.ErrInvalidPattern, pos: 3},
+		{name: "позиция в символах", opts: models.GrepOptions{Pattern: "ош**"}, is: models.ErrInvalidPattern, pos: 3},
+		{name: "-i", opts: models.GrepOptions{Pattern: "ab[z-a]", IgnoreCase: true}, is: models.ErrInvalidPattern, pos: 4},
+		{name: "-i с ошибкой во всем выражении - без позиции", opts: models.GrepOptions{Pattern: "ab(c", IgnoreCase: true}, is: models.ErrInvalidPattern, pos: 0},
+		{name: "-c с контекстом", opts: models.GrepOptions{Pattern: "a", Count: true, Around: 1}, is: models.ErrUnsupportedOptions},
+		{name: "отрицательный контекст", opts: models.GrepOptions{Pattern: "a", Before: -1}, is: models.ErrUnsupportedOptions},
+		{name: "перевод строки", opts: models.GrepOptions{Pattern: "a\nb", Fixed: true}, is: models.ErrUnsupportedOptions},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateOptions(tt.opts)
+			if tt.is == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, tt.is)
+
+			var perr *models.PatternError
+			if errors.As(err, &perr) {
+				assert.Equal(t, tt.pos, perr.Pos)
+			}
+		})
+	}
+}
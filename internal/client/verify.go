@@ -0,0 +1,396 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/sunr3d/quorum-grep/internal/config"
+	"github.com/sunr3d/quorum-grep/internal/interfaces/services"
+	"github.com/sunr3d/quorum-grep/models"
+)
+
+// verifier - выборочная проверка ответов серверов. Чанк обрабатывается
+// одним сервером, поэтому для сравнения его ответ получает второй голос:
+//   - сверка (CROSS_CHECK_RATIO): тот же чанк отправляется другому серверу;
+//     если ответы разошлись, третьим голосом чанк обрабатывается локально;
+//   - локальная проверка (SPOT_CHECK_RATIO): чанк обрабатывается тем же
+//     grepsvc, что и на серверах. Так обнаруживаются и серверы, согласованно
+//     возвращающие одинаковый неверный результат, который сверка не заметит.
+//
+// Эталон - локальный результат, если он есть, иначе совпавший ответ серверов.
+// Каждый сервер, чей ответ сравнивался, получает в репутацию согласие или
+// расхождение с эталоном; сервер с долей расхождений выше порога уходит на
+// карантин. Если принятый ответ расходится с эталоном, принимается эталон.
+type verifier struct {
+	ratio      float64
+	crossRatio float64
+	local      services.GrepService
+	rep        *reputation
+}
+
+// callFunc - обработка чанка на указанном сервере.
+type callFunc func(ctx context.Context, server string, task *models.Task) (models.Result, error)
+
+// vote - ответ на проверяемый чанк; server пуст у локального результата.
+type vote struct {
+	server string
+	result models.Result
+}
+
+// newVerifier - конструктор verifier, nil если проверка выключена.
+func newVerifier(cfg config.VerifyConfig, local services.GrepService) (*verifier, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	if cfg.SpotCheckRatio < 0 || cfg.SpotCheckRatio > 1 {
+		return nil, fmt.Errorf("VERIFY.SPOT_CHECK_RATIO должен быть от 0 до 1, указан %v", cfg.SpotCheckRatio)
+	}
+	if cfg.CrossCheckRatio < 0 || cfg.CrossCheckRatio > 1 {
+		return nil, fmt.Errorf("VERIFY.CROSS_CHECK_RATIO должен быть от 0 до 1, указан %v", cfg.CrossCheckRatio)
+	}
+	if cfg.MaxDisagreement <= 0 || cfg.MaxDisagreement > 1 {
+		return nil, fmt.Errorf("VERIFY.MAX_DISAGREEMENT должен быть больше 0 и не больше 1, указан %v", cfg.MaxDisagreement)
+	}
+	if cfg.MinChecks < 1 {
+		return nil, fmt.Errorf("VERIFY.MIN_CHECKS должен быть не меньше 1, указан %d", cfg.MinChecks)
+	}
+	quarantine, err := time.ParseDuration(cfg.Quarantine)
+	if err != nil || quarantine <= 0 {
+		return nil, fmt.Errorf("некорректный VERIFY.QUARANTINE %q", cfg.Quarantine)
+	}
+
+	path := cfg.StateFile
+	if path == "" {
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			return nil, fmt.Errorf("os.UserCacheDir: %w", err)
+		}
+		path = filepath.Join(dir, "quorum-grep", "reputation.json")
+	}
+
+	rep, err := loadReputation(path)
+	if err != nil {
+		return nil, fmt.Errorf("loadReputation: %w", err)
+	}
+	rep.maxDisagreement = cfg.MaxDisagreement
+	rep.minChecks = int64(cfg.MinChecks)
+	rep.quarantine = quarantine
+
+	return &verifier{
+		ratio:      cfg.SpotCheckRatio,
+		crossRatio: cfg.CrossCheckRatio,
+		local:      local,
+		rep:        rep,
+	}, nil
+}
+
+// check - выборочная проверка успешных чанков. Для сверки используются
+// servers, кроме сервера, ответ которого принят; call отправляет чанк.
+// Выбранные чанки проверяются одновременно в пределах бюджета ctx.
+// Результаты, расходящиеся с эталоном, заменяются им.
+// Возвращает число проверок и расхождений принятых ответов.
+func (v *verifier) check(
+	ctx context.Context,
+	tasks []models.Task,
+	results []models.Result,
+	outcomes []ChunkOutcome,
+	servers []string,
+	call callFunc,
+) (checks, mismatches int) {
+	ctx, span := tracer.Start(ctx, "client.verify")
+	defer func() {
+		span.SetAttributes(attribute.Int("checks", checks), attribute.Int("mismatches", mismatches))
+		span.End()
+	}()
+
+	var (
+		wg                 sync.WaitGroup
+		nChecks, nMismatch atomic.Int64
+	)
+	for i := range outcomes {
+		if outcomes[i].Err != nil {
+			continue
+		}
+		cross := rand.Float64() < v.crossRatio
+		spot := rand.Float64() < v.ratio
+		if !cross && !spot {
+			continue
+		}
+
+		// горутина пишет только в results[i] и outcomes[i]
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			checked, mismatch := v.checkChunk(ctx, &tasks[i], &results[i], &outcomes[i], cross, spot, servers, call)
+			if checked {
+				nChecks.Add(1)
+			}
+			if mismatch {
+				nMismatch.Add(1)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	return int(nChecks.Load()), int(nMismatch.Load())
+}
+
+// checkChunk - проверка одного чанка: сверка на другом сервере (cross)
+// и локальная обработка (spot или при разошедшихся серверах).
+// Возвращает, проверен ли чанк и расходился ли принятый ответ с эталоном.
+func (v *verifier) checkChunk(
+	ctx context.Context,
+	task *models.Task,
+	result *models.Result,
+	outcome *ChunkOutcome,
+	cross, spot bool,
+	servers []string,
+	call callFunc,
+) (checked, mismatch bool) {
+	votes := []vote{{server: outcome.Server, result: *result}}
+	if cross {
+		if other, ok := otherServer(servers, outcome.Server); ok {
+			if res, err := call(ctx, other, task); err == nil {
+				votes = append(votes, vote{server: other, result: res})
+				outcome.CrossChecked = other
+			}
+		}
+	}
+
+	// разошедшиеся серверы рассудит локальный результат
+	if spot || (len(votes) == 2 && !sameResult(votes[0].result, &votes[1].result)) {
+		if local, err := v.local.ProcessChunk(ctx, task); err == nil {
+			votes = append(votes, vote{result: *local})
+			outcome.SpotChecked = true
+		}
+	}
+
+	reference, ok := referenceResult(votes)
+	if !ok {
+		// сравнивать не с чем или ответы серверов разошлись без эталона
+		return false, false
+	}
+
+	for _, vt := range votes {
+		if vt.server != "" {
+			v.rep.record(vt.server, sameResult(vt.result, &reference))
+		}
+	}
+	if sameResult(*result, &reference) {
+		return true, false
+	}
+
+	outcome.Mismatch = true
+	*result = models.Result{
+		Matches:    reference.Matches,
+		MatchCount: reference.MatchCount,
+		TaskIndex:  result.TaskIndex,
+	}
+
+	return true, true
+}
+
+// referenceResult - эталон для проверки: локальный результат, если он есть,
+// иначе совпавший ответ двух серверов.
+func referenceResult(votes []vote) (models.Result, bool) {
+	if len(votes) < 2 {
+		return models.Result{}, false
+	}
+
+	for _, vt := range votes {
+		if vt.server == "" {
+			return vt.result, true
+		}
+	}
+
+	if !sameResult(votes[0].result, &votes[1].result) {
+		return models.Result{}, false
+	}
+
+	return votes[0].result, true
+}
+
+// otherServer - случайный сервер из servers, отличный от exclude.
+func otherServer(servers []string, exclude string) (string, bool) {
+	others := slices.DeleteFunc(slices.Clone(servers), func(s string) bool { return s == exclude })
+	if len(others) == 0 {
+		return "", false
+	}
+
+	return others[rand.IntN(len(others))], true
+}
+
+// sameResult - совпадают ли два результата обработки чанка.
+func sameResult(a models.Result, b *models.Result) bool {
+	if a.MatchCount != b.MatchCount || len(a.Matches) != len(b.Matches) {
+		return false
+	}
+
+	for i, m := range a.Matches {
+		if m.LineNumber != b.Matches[i].LineNumber || !bytes.Equal(m.Content, b.Matches[i].Content) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// reputation - результаты проверок серверов, сохраняемые между запусками.
+// Файл пишется атомарно (через временный файл), при одновременной работе
+// нескольких клиентов сохраняется состояние последнего.
+type reputation struct {
+	path            string
+	maxDisagreement float64
+	minChecks       int64
+	quarantine      time.Duration
+	now             func() time.Time
+
+	mu      sync.Mutex
+	servers map[string]*ServerReputation
+	dirty   bool
+}
+
+// ServerReputation - репутация одного сервера.
+type ServerReputation struct {
+	Checks        int64 `json:"checks"`
+	Disagreements int64 `json:"disagreements"`
+	// Quarantines - сколько раз сервер уходил на карантин.
+	Quarantines      int       `json:"quarantines"`
+	QuarantinedUntil time.Time `json:"quarantined_until,omitzero"`
+}
+
+type reputationFile struct {
+	Servers map[string]*ServerReputation `json:"servers"`
+}
+
+// loadReputation - чтение файла репутации, отсутствующий файл - пустая репутация.
+func loadReputation(path string) (*reputation, error) {
+	r := &reputation{
+		path:    path,
+		now:     time.Now,
+		servers: make(map[string]*ServerReputation),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("os.ReadFile %s: %w", path, err)
+	}
+
+	var file reputationFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal %s: %w", path, err)
+	}
+	for server, rep := range file.Servers {
+		if rep != nil {
+			r.servers[server] = rep
+		}
+	}
+
+	return r, nil
+}
+
+// record - учет проверки ответа server. Если доля расхождений после
+// minChecks проверок выше порога, сервер уходит на карантин.
+func (r *reputation) record(server string, agreed bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rep := r.servers[server]
+	if rep == nil {
+		rep = &ServerReputation{}
+		r.servers[server] = rep
+	}
+
+	rep.Checks++
+	if !agreed {
+		rep.Disagreements++
+	}
+	r.dirty = true
+
+	if rep.Checks >= r.minChecks && float64(rep.Disagreements)/float64(rep.Checks) > r.maxDisagreement {
+		rep.Quarantines++
+		rep.QuarantinedUntil = r.now().Add(r.quarantine)
+		// после карантина сервер проверяется заново
+		rep.Checks, rep.Disagreements = 0, 0
+	}
+}
+
+// trusted - серверы из members, не находящиеся на карантине.
+func (r *reputation) trusted(members []string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	return slices.DeleteFunc(slices.Clone(members), func(server string) bool {
+		rep := r.servers[server]
+		return rep != nil && now.Before(rep.QuarantinedUntil)
+	})
+}
+
+// snapshot - копия репутации серверов.
+func (r *reputation) snapshot() map[string]ServerReputation {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make(map[string]ServerReputation, len(r.servers))
+	for server, rep := range r.servers {
+		out[server] = *rep
+	}
+
+	return out
+}
+
+// save - запись файла, если репутация изменилась.
+func (r *reputation) save() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.dirty {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(reputationFile{Servers: r.servers}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("json.MarshalIndent: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("os.MkdirAll: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), ".reputation-*")
+	if err != nil {
+		return fmt.Errorf("os.CreateTemp: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write %s: %w", tmp.Name(), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close %s: %w", tmp.Name(), err)
+	}
+	if err := os.Rename(tmp.Name(), r.path); err != nil {
+		return fmt.Errorf("os.Rename: %w", err)
+	}
+	r.dirty = false
+
+	return nil
+}
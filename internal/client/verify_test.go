@@ -0,0 +1,270 @@
+package client
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sunr3d/quorum-grep/internal/config"
+	"github.com/sunr3d/quorum-grep/internal/ctxutil"
+	"github.com/sunr3d/quorum-grep/internal/services/grepsvc"
+	"github.com/sunr3d/quorum-grep/models"
+)
+
+func testVerifyConfig(dir string) config.VerifyConfig {
+	return config.VerifyConfig{
+		Enabled:         true,
+		SpotCheckRatio:  1,
+		StateFile:       filepath.Join(dir, "reputation.json"),
+		MaxDisagreement: 0.3,
+		MinChecks:       3,
+		Quarantine:      "1h",
+	}
+}
+
+func TestVerifierCheck(t *testing.T) {
+	v, err := newVerifier(testVerifyConfig(t.TempDir()), grepsvc.New())
+	require.NoError(t, err)
+
+	opts := models.GrepOptions{Pattern: "error"}
+	tasks := []models.Task{
+		{Data: []byte("ok\nfoo error"), Index: 0, LineNumbers: []int64{1, 2}, Options: opts},
+		{Data: []byte("bar error\nend"), Index: 1, LineNumbers: []int64{3, 4}, Options: opts},
+		{Data: []byte("x"), Index: 2, LineNumbers: []int64{5}, Options: opts},
+	}
+	results := []models.Result{
+		{Matches: []models.Match{{Content: []byte("foo error"), LineNumber: 2}}, MatchCount: 1, TaskIndex: 0},
+		// сервер пропустил совпадение
+		{TaskIndex: 1},
+		{},
+	}
+	outcomes := []ChunkOutcome{
+		{Index: 0, Server: "good:1"},
+		{Index: 1, Server: "bad:1"},
+		{Index: 2, Server: "down:1", Err: assert.AnError},
+	}
+
+	checks, mismatches := v.check(context.Background(), tasks, results, outcomes, nil, nil)
+	assert.Equal(t, 2, checks)
+	assert.Equal(t, 1, mismatches)
+
+	assert.True(t, outcomes[0].SpotChecked)
+	assert.False(t, outcomes[0].Mismatch)
+	assert.True(t, outcomes[1].Mismatch)
+	assert.False(t, outcomes[2].SpotChecked, "неуспешные чанки не проверяются")
+
+	// вместо ответа сервера принят локальный результат
+	assert.Equal(t, []models.Match{{Content: []byte("bar error"), LineNumber: 3}}, results[1].Matches)
+	assert.Equal(t, 1, results[1].TaskIndex)
+
+	rep := v.rep.snapshot()
+	assert.Equal(t, ServerReputation{Checks: 1}, rep["good:1"])
+	assert.Equal(t, ServerReputation{Checks: 1, Disagreements: 1}, rep["bad:1"])
+}
+
+func TestVerifierCrossCheck(t *testing.T) {
+	opts := models.GrepOptions{Pattern: "error"}
+	task := models.Task{Data: []byte("ok\nfoo error"), Index: 0, LineNumbers: []int64{1, 2}, Options: opts}
+	right := models.Result{Matches: []models.Match{{Content: []byte("foo error"), LineNumber: 2}}, MatchCount: 1}
+	wrong := models.Result{}
+
+	tests := []struct {
+		name       string
+		accepted   models.Result
+		other      models.Result
+		otherErr   error
+		spot       float64
+		checks     int
+		mismatch   bool
+		spotCheck  bool
+		reputation map[string]ServerReputation
+	}{
+		{
+			name:     "ответы совпали",
+			accepted: right, other: right,
+			checks:     1,
+			reputation: map[string]ServerReputation{"a:1": {Checks: 1}, "b:1": {Checks: 1}},
+		},
+		{
+			name:     "принятый ответ неверен",
+			accepted: wrong, other: right,
+			checks: 1, mismatch: true, spotCheck: true,
+			reputation: map[string]ServerReputation{"a:1": {Checks: 1, Disagreements: 1}, "b:1": {Checks: 1}},
+		},
+		{
+			name:     "неверен ответ второго сервера",
+			accepted: right, other: wrong,
+			checks: 1, spotCheck: true,
+			reputation: map[string]ServerReputation{"a:1": {Checks: 1}, "b:1": {Checks: 1, Disagreements: 1}},
+		},
+		{
+			name:     "согласованно неверные ответы ловит локальная проверка",
+			accepted: wrong, other: wrong, spot: 1,
+			checks: 1, mismatch: true, spotCheck: true,
+			reputation: map[string]ServerReputation{"a:1": {Checks: 1, Disagreements: 1}, "b:1": {Checks: 1, Disagreements: 1}},
+		},
+		{
+			name:     "второй сервер недоступен",
+			accepted: right, otherErr: assert.AnError,
+			reputation: map[string]ServerReputation{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testVerifyConfig(t.TempDir())
+			cfg.SpotCheckRatio, cfg.CrossCheckRatio = tt.spot, 1
+			v, err := newVerifier(cfg, grepsvc.New())
+			require.NoError(t, err)
+
+			var called string
+			call := func(_ context.Context, server string, _ *models.Task) (models.Result, error) {
+				called = server
+				return tt.other, tt.otherErr
+			}
+			results := []models.Result{tt.accepted}
+			outcomes := []ChunkOutcome{{Server: "a:1"}}
+
+			checks, mismatches := v.check(context.Background(), []models.Task{task}, results, outcomes, []string{"a:1", "b:1"}, call)
+			assert.Equal(t, "b:1", called, "сверка на другом сервере")
+			assert.Equal(t, tt.checks, checks)
+			assert.Equal(t, tt.mismatch, mismatches == 1)
+			assert.Equal(t, tt.mismatch, outcomes[0].Mismatch)
+			assert.Equal(t, tt.spotCheck, outcomes[0].SpotChecked)
+			assert.Equal(t, right.Matches, results[0].Matches, "принят верный результат")
+			assert.Equal(t, tt.reputation, v.rep.snapshot())
+		})
+	}
+}
+
+func TestVerifierConcurrentChecks(t *testing.T) {
+	const chunks = 4
+	cfg := testVerifyConfig(t.TempDir())
+	cfg.SpotCheckRatio, cfg.CrossCheckRatio = 0, 1
+	v, err := newVerifier(cfg, grepsvc.New())
+	require.NoError(t, err)
+
+	tasks := make([]models.Task, chunks)
+	results := make([]models.Result, chunks)
+	outcomes := make([]ChunkOutcome, chunks)
+	for i := range chunks {
+		tasks[i] = models.Task{Data: []byte("ok"), Index: i, LineNumbers: []int64{1}, Options: models.GrepOptions{Pattern: "error"}}
+		results[i] = models.Result{TaskIndex: i}
+		outcomes[i] = ChunkOutcome{Index: i, Server: "a:1"}
+	}
+
+	// каждая сверка ждет, пока начнутся все остальные: при проверке
+	// по одному чанку за раз вызовы упрутся в дедлайн
+	var started atomic.Int64
+	call := func(ctx context.Context, _ string, _ *models.Task) (models.Result, error) {
+		started.Add(1)
+		for started.Load() < chunks {
+			if err := ctxutil.Sleep(ctx, time.Millisecond); err != nil {
+				return models.Result{}, err
+			}
+		}
+		return models.Result{}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	checks, mismatches := v.check(ctx, tasks, results, outcomes, []string{"a:1", "b:1"}, call)
+	assert.Equal(t, chunks, checks)
+	assert.Zero(t, mismatches)
+	assert.Equal(t, ServerReputation{Checks: chunks}, v.rep.snapshot()["b:1"])
+}
+
+func TestReputation(t *testing.T) {
+	dir := t.TempDir()
+	v, err := newVerifier(testVerifyConfig(dir), grepsvc.New())
+	require.NoError(t, err)
+
+	now := time.Unix(1_700_000_000, 0)
+	r := v.rep
+	r.now = func() time.Time { return now }
+	members := []string{"a:1", "b:1"}
+
+	// до MIN_CHECKS проверок карантина нет даже при расхождениях
+	r.record("a:1", false)
+	r.record("a:1", true)
+	assert.Equal(t, members, r.trusted(members))
+
+	// 1 расхождение из 3 - выше порога 0.3
+	r.record("a:1", true)
+	assert.Equal(t, []string{"b:1"}, r.trusted(members))
+	assert.Equal(t, 1, r.snapshot()["a:1"].Quarantines)
+
+	require.NoError(t, r.save())
+	loaded, err := loadReputation(filepath.Join(dir, "reputation.json"))
+	require.NoError(t, err)
+	loaded.now = r.now
+	assert.Equal(t, []string{"b:1"}, loaded.trusted(members), "карантин сохраняется между запусками")
+
+	now = now.Add(time.Hour)
+	assert.Equal(t, members, r.trusted(members), "после карантина сервер возвращается")
+	assert.Zero(t, r.snapshot()["a:1"].Checks, "и проверяется заново")
+}
+
+func TestNewVerifier(t *testing.T) {
+	dir := t.TempDir()
+	broken := filepath.Join(dir, "broken.json")
+	require.NoError(t, os.WriteFile(broken, []byte("{"), 0o600))
+
+	tests := []struct {
+		name    string
+		modify  func(*config.VerifyConfig)
+		wantErr bool
+	}{
+		{name: "корректный", modify: func(*config.VerifyConfig) {}},
+		{name: "доля проверок больше 1", modify: func(c *config.VerifyConfig) { c.SpotCheckRatio = 1.5 }, wantErr: true},
+		{name: "отрицательная доля сверок", modify: func(c *config.VerifyConfig) { c.CrossCheckRatio = -0.1 }, wantErr: true},
+		{name: "нулевой порог", modify: func(c *config.VerifyConfig) { c.MaxDisagreement = 0 }, wantErr: true},
+		{name: "MIN_CHECKS 0", modify: func(c *config.VerifyConfig) { c.MinChecks = 0 }, wantErr: true},
+		{name: "некорректный карантин", modify: func(c *config.VerifyConfig) { c.Quarantine = "soon" }, wantErr: true},
+		{name: "поврежденный файл", modify: func(c *config.VerifyConfig) { c.StateFile = broken }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testVerifyConfig(dir)
+			tt.modify(&cfg)
+
+			_, err := newVerifier(cfg, grepsvc.New())
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestClientAllQuarantined(t *testing.T) {
+	members := []string{"127.0.0.1:1", "127.0.0.1:2"}
+	c, err := New(&config.Config{Client: config.ClientConfig{
+		ServerList: members,
+		Timeout:    "1s",
+		Quorum:     config.QuorumConfig{Policy: QuorumAll},
+		Verify:     testVerifyConfig(t.TempDir()),
+	}})
+	require.NoError(t, err)
+	defer c.Close()
+
+	for _, server := range members {
+		for range 3 {
+			c.verify.rep.record(server, false)
+		}
+	}
+	require.Empty(t, c.verify.rep.trusted(members))
+
+	// все серверы на карантине: пустой результат не считается кворумом
+	_, err = c.Search(context.Background(), "input", strings.NewReader("error\n"), models.GrepOptions{Pattern: "error"})
+	assert.ErrorIs(t, err, ErrNoQuorum)
+}
@@ -8,10 +8,160 @@ type Config struct {
 
 type GRPCServerConfig struct {
 	Port int `mapstructure:"PORT"`
+	// MaxMessageSize - максимальный размер входящего сообщения в байтах.
+	MaxMessageSize int `mapstructure:"MAX_MESSAGE_SIZE"`
+	// MaxConcurrentChunks - сколько чанков обрабатывается одновременно,
+	// сверх лимита запросы отклоняются с RESOURCE_EXHAUSTED.
+	MaxConcurrentChunks int `mapstructure:"MAX_CONCURRENT_CHUNKS"`
+	// MaxChunkDuration - бюджет времени на обработку одного чанка.
+	MaxChunkDuration string `mapstructure:"MAX_CHUNK_DURATION"`
+	// MaxResponseSize - максимальный размер ответа в байтах.
+	MaxResponseSize int `mapstructure:"MAX_RESPONSE_SIZE"`
+	// MaxQueuedPerClient - сколько чанков клиента может ждать свободного слота.
+	MaxQueuedPerClient int `mapstructure:"MAX_QUEUED_PER_CLIENT"`
+	// RateLimit - лимит чанков в секунду на клиента, 0 - без лимита.
+	RateLimit float64 `mapstructure:"RATE_LIMIT"`
+	// RateBurst - сколько чанков клиент может отправить разом сверх RateLimit.
+	RateBurst int `mapstructure:"RATE_BURST"`
+	// MetricsPort - порт HTTP сервера с /metrics, 0 - метрики не отдаются.
+	MetricsPort int                `mapstructure:"METRICS_PORT"`
+	TLS         ServerTLSConfig    `mapstructure:"TLS"`
+	Auth        ServerAuthConfig   `mapstructure:"AUTH"`
+	Admin       ServerAdminConfig  `mapstructure:"ADMIN"`
+	Faults      ServerFaultsConfig `mapstructure:"FAULTS"`
+	Tracing     TracingConfig      `mapstructure:"TRACING"`
+}
+
+type ServerTLSConfig struct {
+	CertFile string `mapstructure:"CERT_FILE"`
+	KeyFile  string `mapstructure:"KEY_FILE"`
+	// ClientCAFile - CA сертификатов клиентов, включает mTLS.
+	ClientCAFile string `mapstructure:"CLIENT_CA_FILE"`
+	// AllowedSubjects - разрешенные CN/DNS SAN клиентов, пусто - любой от ClientCAFile.
+	AllowedSubjects []string `mapstructure:"ALLOWED_SUBJECTS"`
+}
+
+type ServerAuthConfig struct {
+	// TokenFile - YAML файл со статическими токенами и их правами.
+	TokenFile string `mapstructure:"TOKEN_FILE"`
+	// JWTKeyFile - ключ для проверки JWT, подписанных HS256.
+	JWTKeyFile string `mapstructure:"JWT_KEY_FILE"`
+}
+
+// ServerAdminConfig - доступ к AdminService. Сервис регистрируется, только
+// если задана аутентификация токенами, Subjects или Enabled.
+type ServerAdminConfig struct {
+	// Enabled - AdminService без аутентификации, только для доверенной сети.
+	Enabled bool `mapstructure:"ENABLED"`
+	// Subjects - CN/DNS SAN клиентов mTLS, которым доступен AdminService.
+	// Токенам вместо этого нужны права на "/adminsvc.AdminService/*".
+	Subjects []string `mapstructure:"SUBJECTS"`
+}
+
+type ServerFaultsConfig struct {
+	// Enabled - внесение неисправностей; переключаются они через AdminService.
+	Enabled bool `mapstructure:"ENABLED"`
+	// File - YAML файл с неисправностями по методам, пусто - без неисправностей
+	// до вызова AdminService.SetFaults.
+	File string `mapstructure:"FILE"`
 }
 
 type ClientConfig struct {
-	ServerList []string `mapstructure:"SERVER_LIST"`
-	Timeout    string   `mapstructure:"TIMEOUT"`
-	ChunkSize  int      `mapstructure:"CHUNK_SIZE"`
+	// ServerList - серверы для DISCOVERY.MODE static.
+	ServerList []string        `mapstructure:"SERVER_LIST"`
+	Discovery  DiscoveryConfig `mapstructure:"DISCOVERY"`
+	Quorum     QuorumConfig    `mapstructure:"QUORUM"`
+	Timeout    string          `mapstructure:"TIMEOUT"`
+	ChunkSize  int             `mapstructure:"CHUNK_SIZE"`
+	ClientID   string          `mapstructure:"CLIENT_ID"`
+	TLS        ClientTLSConfig `mapstructure:"TLS"`
+	// AuthToken - bearer токен для серверов, пусто - из QUORUM_GREP_TOKEN.
+	AuthToken   string            `mapstructure:"AUTH_TOKEN"`
+	HealthCheck HealthCheckConfig `mapstructure:"HEALTH_CHECK"`
+	Hedge       HedgeConfig       `mapstructure:"HEDGE"`
+	Verify      VerifyConfig      `mapstructure:"VERIFY"`
+	Fallback    FallbackConfig    `mapstructure:"FALLBACK"`
+	Tracing     TracingConfig     `mapstructure:"TRACING"`
+}
+
+type DiscoveryConfig struct {
+	// Mode - источник списка серверов: static (SERVER_LIST), dns, srv или file.
+	Mode string `mapstructure:"MODE"`
+	// Target - host:port для dns (A/AAAA записи), имя _service._proto.domain для srv.
+	Target string `mapstructure:"TARGET"`
+	// File - файл с адресами серверов host:port по одному на строку для file.
+	File string `mapstructure:"FILE"`
+	// Refresh - период повторного разрешения для dns и srv.
+	Refresh string `mapstructure:"REFRESH"`
+}
+
+type QuorumConfig struct {
+	// Policy - сколько чанков должно быть обработано успешно:
+	// majority, all, count (COUNT) или weighted (большинство голосов WEIGHTS).
+	Policy string `mapstructure:"POLICY"`
+	Count  int    `mapstructure:"COUNT"`
+	// Weights - голоса серверов для weighted, у остальных серверов голос 1.
+	Weights []ServerWeight `mapstructure:"WEIGHTS"`
+}
+
+type ServerWeight struct {
+	Server string `mapstructure:"SERVER"`
+	Weight int    `mapstructure:"WEIGHT"`
+}
+
+type HealthCheckConfig struct {
+	Enabled  bool   `mapstructure:"ENABLED"`
+	Interval string `mapstructure:"INTERVAL"`
+	Timeout  string `mapstructure:"TIMEOUT"`
+}
+
+type HedgeConfig struct {
+	Enabled    bool    `mapstructure:"ENABLED"`
+	Delay      string  `mapstructure:"DELAY"`
+	Percentile float64 `mapstructure:"PERCENTILE"`
+	MaxHedges  int     `mapstructure:"MAX_HEDGES"`
+}
+
+type VerifyConfig struct {
+	Enabled bool `mapstructure:"ENABLED"`
+	// SpotCheckRatio - доля успешных чанков, повторно обрабатываемых локально.
+	SpotCheckRatio float64 `mapstructure:"SPOT_CHECK_RATIO"`
+	// CrossCheckRatio - доля успешных чанков, отправляемых еще одному серверу
+	// для сверки ответов.
+	CrossCheckRatio float64 `mapstructure:"CROSS_CHECK_RATIO"`
+	// StateFile - файл с репутацией серверов, пусто - в каталоге кэша пользователя.
+	StateFile string `mapstructure:"STATE_FILE"`
+	// MaxDisagreement - доля расхождений, выше которой сервер уходит на карантин.
+	MaxDisagreement float64 `mapstructure:"MAX_DISAGREEMENT"`
+	// MinChecks - сколько проверок сервера нужно до решения о карантине.
+	MinChecks int `mapstructure:"MIN_CHECKS"`
+	// Quarantine - на сколько сервер исключается из списка.
+	Quarantine string `mapstructure:"QUARANTINE"`
+}
+
+type FallbackConfig struct {
+	// Enabled - обрабатывать локально чанки, не обработанные серверами,
+	// даже если кворум не достигнут.
+	Enabled bool `mapstructure:"ENABLED"`
+	// AutoMaxBytes - для входа не больше стольких байт локальная обработка
+	// включается автоматически, 0 - только по ENABLED.
+	AutoMaxBytes int64 `mapstructure:"AUTO_MAX_BYTES"`
+}
+
+type ClientTLSConfig struct {
+	Enabled bool `mapstructure:"ENABLED"`
+	// CAFile - CA сертификатов серверов, пусто - системные корневые.
+	CAFile string `mapstructure:"CA_FILE"`
+	// CertFile, KeyFile - сертификат клиента для mTLS.
+	CertFile   string `mapstructure:"CERT_FILE"`
+	KeyFile    string `mapstructure:"KEY_FILE"`
+	ServerName string `mapstructure:"SERVER_NAME"`
+}
+
+type TracingConfig struct {
+	// Exporter - куда писать спаны: stdout, file, пусто - трассировка выключена.
+	Exporter string `mapstructure:"EXPORTER"`
+	File     string `mapstructure:"FILE"`
+	// SampleRatio - доля трассируемых запросов, 0 - все.
+	SampleRatio float64 `mapstructure:"SAMPLE_RATIO"`
 }
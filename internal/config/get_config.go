@@ -27,6 +27,25 @@ func setDefaults(cfg *config.Config) {
 	cfg.SetDefault("LOG_LEVEL", "info")
 
 	cfg.SetDefault("CLIENT.SERVER_LIST", []string{"localhost:50051", "localhost:50052", "localhost:50053"})
+	cfg.SetDefault("CLIENT.DISCOVERY.MODE", "static")
+	cfg.SetDefault("CLIENT.DISCOVERY.REFRESH", "30s")
+	cfg.SetDefault("CLIENT.QUORUM.POLICY", "majority")
 	cfg.SetDefault("CLIENT.TIMEOUT", "30s")
 	cfg.SetDefault("CLIENT.CHUNK_SIZE", 1024)
+	cfg.SetDefault("CLIENT.TLS.ENABLED", false)
+	cfg.SetDefault("CLIENT.HEALTH_CHECK.ENABLED", true)
+	cfg.SetDefault("CLIENT.HEALTH_CHECK.INTERVAL", "5s")
+	cfg.SetDefault("CLIENT.HEALTH_CHECK.TIMEOUT", "1s")
+	cfg.SetDefault("CLIENT.HEDGE.ENABLED", false)
+	cfg.SetDefault("CLIENT.HEDGE.DELAY", "500ms")
+	cfg.SetDefault("CLIENT.HEDGE.PERCENTILE", 95)
+	cfg.SetDefault("CLIENT.HEDGE.MAX_HEDGES", 1)
+	cfg.SetDefault("CLIENT.VERIFY.ENABLED", false)
+	cfg.SetDefault("CLIENT.VERIFY.SPOT_CHECK_RATIO", 0.05)
+	cfg.SetDefault("CLIENT.VERIFY.CROSS_CHECK_RATIO", 0.1)
+	cfg.SetDefault("CLIENT.VERIFY.MAX_DISAGREEMENT", 0.1)
+	cfg.SetDefault("CLIENT.VERIFY.MIN_CHECKS", 5)
+	cfg.SetDefault("CLIENT.VERIFY.QUARANTINE", "1h")
+	cfg.SetDefault("CLIENT.FALLBACK.ENABLED", false)
+	cfg.SetDefault("CLIENT.FALLBACK.AUTO_MAX_BYTES", 0)
 }
@@ -0,0 +1,20 @@
+// Package ctxutil - вспомогательные функции для работы с context.
+package ctxutil
+
+import (
+	"context"
+	"time"
+)
+
+// Sleep - пауза на d, прерываемая отменой ctx.
+func Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,104 @@
+package discovery
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/sunr3d/quorum-grep/internal/config"
+)
+
+// Источники списка серверов (DISCOVERY.MODE).
+const (
+	ModeStatic = "static"
+	ModeDNS    = "dns"
+	ModeSRV    = "srv"
+	ModeFile   = "file"
+)
+
+const defaultRefresh = 30 * time.Second
+
+// Discovery - источник списка серверов. Список может меняться между
+// вызовами Servers, поэтому клиент берет снимок на каждый поиск.
+type Discovery interface {
+	// Servers - текущие серверы host:port, отсортированы, без повторов.
+	Servers() []string
+	Close() error
+}
+
+// New - источник по cfg.Mode; для static используется static (SERVER_LIST).
+// Для dns, srv и file ждет первого списка и возвращает ошибку, если он пуст.
+func New(cfg config.DiscoveryConfig, static []string) (Discovery, error) {
+	refresh, _ := time.ParseDuration(cfg.Refresh)
+	if refresh <= 0 {
+		refresh = defaultRefresh
+	}
+
+	switch cfg.Mode {
+	case ModeStatic, "":
+		if len(static) == 0 {
+			return nil, fmt.Errorf("не указаны серверы (SERVER_LIST)")
+		}
+		return Static(static...), nil
+	case ModeDNS:
+		return newDNS(cfg.Target, refresh)
+	case ModeSRV:
+		return newSRV(cfg.Target, refresh)
+	case ModeFile:
+		return newFile(cfg.File)
+	default:
+		return nil, fmt.Errorf("неизвестный DISCOVERY.MODE %q, ожидается %s, %s, %s или %s",
+			cfg.Mode, ModeStatic, ModeDNS, ModeSRV, ModeFile)
+	}
+}
+
+type static []string
+
+// Static - неизменный список серверов.
+func Static(servers ...string) Discovery {
+	return static(normalize(servers))
+}
+
+func (s static) Servers() []string {
+	return s
+}
+
+func (s static) Close() error {
+	return nil
+}
+
+// normalize - отсортированная копия без повторов, чтобы порядок и
+// распределение чанков по серверам не зависели от порядка в источнике.
+func normalize(servers []string) []string {
+	out := slices.Clone(servers)
+	slices.Sort(out)
+
+	return slices.Compact(out)
+}
+
+// list - список серверов, обновляемый из фоновой горутины.
+type list struct {
+	mu      sync.Mutex
+	servers []string
+}
+
+func (l *list) Servers() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.servers
+}
+
+// set - новый список; пустой список не заменяет текущий, чтобы временный
+// сбой разрешения имен не оставил клиента без серверов.
+func (l *list) set(servers []string) {
+	if len(servers) == 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.servers = normalize(servers)
+}
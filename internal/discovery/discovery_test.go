@@ -0,0 +1,108 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sunr3d/quorum-grep/internal/config"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.DiscoveryConfig
+		static  []string
+		wantErr bool
+	}{
+		{name: "static по умолчанию", static: []string{"b:1", "a:1", "b:1"}},
+		{name: "static без серверов", cfg: config.DiscoveryConfig{Mode: ModeStatic}, wantErr: true},
+		{name: "dns без имени", cfg: config.DiscoveryConfig{Mode: ModeDNS}, wantErr: true},
+		{name: "srv без имени", cfg: config.DiscoveryConfig{Mode: ModeSRV}, wantErr: true},
+		{name: "file без пути", cfg: config.DiscoveryConfig{Mode: ModeFile}, wantErr: true},
+		{name: "нет файла", cfg: config.DiscoveryConfig{Mode: ModeFile, File: filepath.Join(t.TempDir(), "nope")}, wantErr: true},
+		{name: "неизвестный режим", cfg: config.DiscoveryConfig{Mode: "consul"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := New(tt.cfg, tt.static)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			defer d.Close()
+			assert.Equal(t, []string{"a:1", "b:1"}, d.Servers())
+		})
+	}
+}
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "servers")
+	write := func(data string, mtime time.Time) {
+		require.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+		require.NoError(t, os.Chtimes(path, mtime, mtime))
+	}
+
+	start := time.Now()
+	write("# кластер\nb:1\n\n a:1  # основной\n", start)
+
+	d, err := newFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a:1", "b:1"}, d.Servers())
+
+	write("a:1\nb:1\nc:1\n", start.Add(time.Second))
+	assert.Equal(t, []string{"a:1", "b:1"}, d.Servers(), "до интервала проверки файл не перечитывается")
+	d.checkedAt = time.Time{}
+	assert.Equal(t, []string{"a:1", "b:1", "c:1"}, d.Servers())
+
+	write("# пусто\n", start.Add(2*time.Second))
+	d.checkedAt = time.Time{}
+	assert.Equal(t, []string{"a:1", "b:1", "c:1"}, d.Servers(), "пустой файл не заменяет список")
+
+	require.NoError(t, os.Remove(path))
+	d.checkedAt = time.Time{}
+	assert.Equal(t, []string{"a:1", "b:1", "c:1"}, d.Servers())
+}
+
+func TestSRV(t *testing.T) {
+	records := []*net.SRV{{Target: "grep-2.local.", Port: 50051}, {Target: "grep-1.local.", Port: 50052}}
+	var lookupErr error
+	orig := lookupSRV
+	lookupSRV = func(_ context.Context, service, proto, name string) (string, []*net.SRV, error) {
+		assert.Equal(t, "_grep._tcp.local", name)
+		return "", records, lookupErr
+	}
+	defer func() { lookupSRV = orig }()
+
+	d, err := newSRV("_grep._tcp.local", time.Hour)
+	require.NoError(t, err)
+	defer d.Close()
+	assert.Equal(t, []string{"grep-1.local:50052", "grep-2.local:50051"}, d.Servers())
+
+	records = append(records, &net.SRV{Target: "grep-3.local.", Port: 50051})
+	require.NoError(t, d.resolve())
+	assert.Len(t, d.Servers(), 3)
+
+	lookupErr = errors.New("SERVFAIL")
+	assert.Error(t, d.resolve())
+	assert.Len(t, d.Servers(), 3, "при ошибке остается предыдущий список")
+}
+
+func TestDNS(t *testing.T) {
+	d, err := newDNS("localhost:50051", time.Hour)
+	if err != nil {
+		t.Skipf("localhost не разрешается: %v", err)
+	}
+	defer d.Close()
+
+	assert.Contains(t, d.Servers(), "127.0.0.1:50051")
+}
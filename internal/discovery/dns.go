@@ -0,0 +1,211 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/dns"
+	"google.golang.org/grpc/serviceconfig"
+)
+
+// resolveTimeout - сколько ждать первого разрешения имени при создании.
+const resolveTimeout = 10 * time.Second
+
+// dnsDiscovery - серверы из A/AAAA записей имени host:port. Имя разрешает
+// DNS резолвер gRPC, как для dns:/// адресов, поэтому каждый адрес
+// реплики сервиса (например, docker compose --scale) - отдельный сервер.
+// Резолвер gRPC повторяет запрос не чаще раза в 30 секунд.
+type dnsDiscovery struct {
+	// resolver.ClientConn встроен, как рекомендует gRPC для своих реализаций;
+	// резолвер вызывает только методы ниже.
+	resolver.ClientConn
+	list
+
+	r    resolver.Resolver
+	done chan struct{}
+
+	readyOnce sync.Once
+	ready     chan struct{}
+	errMu     sync.Mutex
+	lastErr   error
+}
+
+func newDNS(target string, refresh time.Duration) (*dnsDiscovery, error) {
+	if target == "" {
+		return nil, errors.New("не указан DISCOVERY.TARGET")
+	}
+
+	u, err := url.Parse("dns:///" + target)
+	if err != nil {
+		return nil, fmt.Errorf("url.Parse: %w", err)
+	}
+
+	d := &dnsDiscovery{
+		done:  make(chan struct{}),
+		ready: make(chan struct{}),
+	}
+	d.r, err = dns.NewBuilder().Build(resolver.Target{URL: *u}, d, resolver.BuildOptions{DisableServiceConfig: true})
+	if err != nil {
+		return nil, fmt.Errorf("resolver.Build: %w", err)
+	}
+
+	select {
+	case <-d.ready:
+	case <-time.After(resolveTimeout):
+	}
+	if len(d.Servers()) == 0 {
+		d.r.Close()
+		return nil, fmt.Errorf("нет адресов для %s: %w", target, d.err())
+	}
+
+	go d.refresh(refresh)
+
+	return d, nil
+}
+
+// refresh - периодический запрос повторного разрешения.
+func (d *dnsDiscovery) refresh(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.done:
+			return
+		case <-ticker.C:
+			d.r.ResolveNow(resolver.ResolveNowOptions{})
+		}
+	}
+}
+
+// UpdateState - новый список адресов от резолвера.
+func (d *dnsDiscovery) UpdateState(state resolver.State) error {
+	var servers []string
+	for _, addr := range state.Addresses {
+		servers = append(servers, addr.Addr)
+	}
+	if len(servers) == 0 {
+		for _, ep := range state.Endpoints {
+			for _, addr := range ep.Addresses {
+				servers = append(servers, addr.Addr)
+			}
+		}
+	}
+
+	d.set(servers)
+	d.readyOnce.Do(func() { close(d.ready) })
+
+	return nil
+}
+
+// ReportError - ошибка разрешения; текущий список сохраняется.
+func (d *dnsDiscovery) ReportError(err error) {
+	d.errMu.Lock()
+	d.lastErr = err
+	d.errMu.Unlock()
+
+	d.readyOnce.Do(func() { close(d.ready) })
+}
+
+func (d *dnsDiscovery) ParseServiceConfig(string) *serviceconfig.ParseResult {
+	return &serviceconfig.ParseResult{Err: errors.New("service config не поддерживается")}
+}
+
+func (d *dnsDiscovery) err() error {
+	d.errMu.Lock()
+	defer d.errMu.Unlock()
+
+	if d.lastErr == nil {
+		return errors.New("пустой ответ DNS")
+	}
+
+	return d.lastErr
+}
+
+func (d *dnsDiscovery) Close() error {
+	close(d.done)
+	d.r.Close()
+
+	return nil
+}
+
+// lookupSRV - запрос SRV записей, подменяется в тестах.
+var lookupSRV = net.DefaultResolver.LookupSRV
+
+// srvDiscovery - серверы из SRV записей имени _service._proto.domain:
+// порт каждого сервера берется из записи.
+type srvDiscovery struct {
+	list
+
+	name string
+	done chan struct{}
+}
+
+func newSRV(name string, refresh time.Duration) (*srvDiscovery, error) {
+	if name == "" {
+		return nil, errors.New("не указан DISCOVERY.TARGET")
+	}
+
+	d := &srvDiscovery{
+		name: name,
+		done: make(chan struct{}),
+	}
+	if err := d.resolve(); err != nil {
+		return nil, err
+	}
+
+	go d.refresh(refresh)
+
+	return d, nil
+}
+
+func (d *srvDiscovery) refresh(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.done:
+			return
+		case <-ticker.C:
+			// при ошибке остается предыдущий список
+			_ = d.resolve()
+		}
+	}
+}
+
+func (d *srvDiscovery) resolve() error {
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+	defer cancel()
+
+	_, records, err := lookupSRV(ctx, "", "", d.name)
+	if err != nil {
+		return fmt.Errorf("LookupSRV %s: %w", d.name, err)
+	}
+	if len(records) == 0 {
+		return fmt.Errorf("нет SRV записей для %s", d.name)
+	}
+
+	servers := make([]string, 0, len(records))
+	for _, rec := range records {
+		host := strings.TrimSuffix(rec.Target, ".")
+		servers = append(servers, net.JoinHostPort(host, strconv.Itoa(int(rec.Port))))
+	}
+	d.set(servers)
+
+	return nil
+}
+
+func (d *srvDiscovery) Close() error {
+	close(d.done)
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package discovery
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fileCheckInterval - как часто проверяется изменение файла на диске.
+const fileCheckInterval = time.Second
+
+// fileDiscovery - серверы из файла, по одному host:port на строку;
+// пустые строки и комментарии # пропускаются. Файл перечитывается при
+// изменении mtime, проверка - не чаще fileCheckInterval. Если новый файл
+// пуст или не читается, продолжает использоваться предыдущий список.
+type fileDiscovery struct {
+	path string
+
+	mu        sync.Mutex
+	servers   []string
+	modTime   time.Time
+	checkedAt time.Time
+}
+
+func newFile(path string) (*fileDiscovery, error) {
+	if path == "" {
+		return nil, errors.New("не указан DISCOVERY.FILE")
+	}
+
+	d := &fileDiscovery{path: path}
+	if err := d.reload(); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+func (d *fileDiscovery) Servers() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if time.Since(d.checkedAt) >= fileCheckInterval {
+		_ = d.reload()
+	}
+
+	return d.servers
+}
+
+func (d *fileDiscovery) reload() error {
+	d.checkedAt = time.Now()
+
+	info, err := os.Stat(d.path)
+	if err != nil {
+		return fmt.Errorf("os.Stat %s: %w", d.path, err)
+	}
+	if info.ModTime().Equal(d.modTime) {
+		return nil
+	}
+
+	data, err := os.ReadFile(d.path)
+	if err != nil {
+		return fmt.Errorf("os.ReadFile %s: %w", d.path, err)
+	}
+
+	servers := parseServers(data)
+	if len(servers) == 0 {
+		return fmt.Errorf("в %s нет адресов серверов", d.path)
+	}
+
+	d.servers = normalize(servers)
+	d.modTime = info.ModTime()
+
+	return nil
+}
+
+func parseServers(data []byte) []string {
+	var servers []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			servers = append(servers, line)
+		}
+	}
+
+	return servers
+}
+
+func (d *fileDiscovery) Close() error {
+	return nil
+}
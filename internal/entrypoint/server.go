@@ -2,22 +2,41 @@ package entrypoint
 
 import (
 	"context"
+	"fmt"
+
+	"github.com/wb-go/wbf/zlog"
 
 	"github.com/sunr3d/quorum-grep/internal/config"
 	grpchandlers "github.com/sunr3d/quorum-grep/internal/handlers/grpc"
 	"github.com/sunr3d/quorum-grep/internal/server"
 	"github.com/sunr3d/quorum-grep/internal/services/grepsvc"
+	"github.com/sunr3d/quorum-grep/internal/tracing"
 	pbg "github.com/sunr3d/quorum-grep/proto/grepsvc"
 )
 
 func RunServer(ctx context.Context, cfg *config.GRPCServerConfig) error {
+	shutdownTracing, err := tracing.Setup(cfg.Tracing, "grep-server")
+	if err != nil {
+		return fmt.Errorf("tracing.Setup: %w", err)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			zlog.Logger.Warn().Err(err).Msg("Не удалось сохранить спаны")
+		}
+	}()
+
 	svc := grepsvc.New()
 
 	handler := grpchandlers.New(svc)
 
-	srv := server.New(cfg)
+	srv, err := server.New(cfg)
+	if err != nil {
+		return fmt.Errorf("server.New: %w", err)
+	}
 
 	pbg.RegisterGrepServiceServer(srv.GetGRPCServer(), handler)
+	srv.RegisterPatternCache(svc.PatternCacheStats)
+	srv.SetCapabilities(svc.Capabilities())
 
 	return srv.Run(ctx)
 }
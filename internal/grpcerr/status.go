@@ -0,0 +1,71 @@
+package grpcerr
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/sunr3d/quorum-grep/models"
+)
+
+// ToStatus - преобразование ошибки сервиса в gRPC status с деталями.
+// Причина ошибки передается в google.rpc.ErrorInfo, чтобы клиент мог
+// восстановить типизированную ошибку; metadata попадает туда же.
+func ToStatus(ctx context.Context, err error, metadata map[string]string) error {
+	reason := models.ErrorReason(err)
+
+	var code codes.Code
+	switch reason {
+	case models.ReasonInvalidPattern:
+		code = codes.InvalidArgument
+	case models.ReasonLimitExceeded, models.ReasonOverloaded, models.ReasonRateLimited:
+		code = codes.ResourceExhausted
+	case models.ReasonUnauthenticated:
+		code = codes.Unauthenticated
+	case models.ReasonPermissionDenied:
+		code = codes.PermissionDenied
+	case models.ReasonCancelled:
+		code = codes.Canceled
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			code = codes.DeadlineExceeded
+		}
+	default:
+		return status.Errorf(codes.Internal, "%v", err)
+	}
+
+	details := []protoadapt.MessageV1{
+		&errdetails.ErrorInfo{
+			Reason:   reason,
+			Domain:   models.ErrorDomain,
+			Metadata: metadata,
+		},
+	}
+
+	var rateErr *models.RateLimitError
+	if errors.As(err, &rateErr) {
+		details = append(details, &errdetails.RetryInfo{
+			RetryDelay: durationpb.New(rateErr.RetryAfter),
+		})
+	}
+
+	if reason == models.ReasonInvalidPattern {
+		details = append(details, &errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{{
+				Field:       "options.pattern",
+				Description: err.Error(),
+			}},
+		})
+	}
+
+	st, detailsErr := status.New(code, err.Error()).WithDetails(details...)
+	if detailsErr != nil {
+		return status.Error(code, err.Error())
+	}
+
+	return st.Err()
+}
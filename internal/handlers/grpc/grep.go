@@ -4,13 +4,20 @@ import (
 	"context"
 
 	"github.com/wb-go/wbf/zlog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
+	"github.com/sunr3d/quorum-grep/internal/grpcerr"
 	"github.com/sunr3d/quorum-grep/models"
 	pbg "github.com/sunr3d/quorum-grep/proto/grepsvc"
 )
 
 // ProcessChunk - ручка gRPC для обработки куска данных.
 func (h *handler) ProcessChunk(ctx context.Context, req *pbg.ChunkRequest) (*pbg.ChunkResponse, error) {
+	if req.Options == nil {
+		return nil, status.Error(codes.InvalidArgument, "не указаны опции поиска")
+	}
+
 	zlog.Logger.Info().
 		Str("task_id", req.TaskId).
 		Int("chunk_index", int(req.ChunkIndex)).
@@ -40,11 +47,8 @@ func (h *handler) ProcessChunk(ctx context.Context, req *pbg.ChunkRequest) (*pbg
 		zlog.Logger.Error().
 			Err(err).
 			Str("task_id", req.TaskId).
-			Err(err).
 			Msg("Ошибка при обработке куска данных")
-		return &pbg.ChunkResponse{
-			TaskId: req.TaskId,
-		}, nil
+		return nil, grpcerr.ToStatus(ctx, err, map[string]string{"task_id": req.TaskId})
 	}
 
 	matches := make([]*pbg.Match, len(result.Matches))
@@ -8,4 +8,6 @@ import (
 
 type GrepService interface {
 	ProcessChunk(ctx context.Context, task *models.Task) (*models.Result, error)
+	PatternCacheStats() models.PatternCacheStats
+	Capabilities() models.Capabilities
 }
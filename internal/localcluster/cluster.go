@@ -0,0 +1,181 @@
+// Package localcluster - кластер серверов grep в текущем процессе на
+// loopback: для тестов клиента без docker compose и для mygrep
+// --local-cluster. Каждый узел - полноценный server.Server (health, лимиты)
+// с GrepService и включенным внесением неисправностей (server.MethodFaults).
+package localcluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"sync"
+
+	"google.golang.org/grpc"
+
+	"github.com/sunr3d/quorum-grep/internal/config"
+	grpchandlers "github.com/sunr3d/quorum-grep/internal/handlers/grpc"
+	"github.com/sunr3d/quorum-grep/internal/server"
+	"github.com/sunr3d/quorum-grep/internal/services/grepsvc"
+	pbg "github.com/sunr3d/quorum-grep/proto/grepsvc"
+)
+
+// Cluster - запущенные узлы.
+type Cluster struct {
+	nodes []*Node
+}
+
+// Start - запуск n узлов на 127.0.0.1 со случайными портами.
+// cfg - настройки серверов, nil - значения по умолчанию; Port не используется.
+func Start(n int, cfg *config.GRPCServerConfig) (*Cluster, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("число узлов должно быть не меньше 1, указано %d", n)
+	}
+	if cfg == nil {
+		cfg = &config.GRPCServerConfig{}
+	}
+	// неисправности включены, но без правил не вносятся до SetFaults
+	nodeCfg := *cfg
+	nodeCfg.Faults.Enabled = true
+	cfg = &nodeCfg
+
+	c := &Cluster{}
+	for range n {
+		node, err := startNode(cfg)
+		if err != nil {
+			c.Close()
+			return nil, fmt.Errorf("startNode: %w", err)
+		}
+		c.nodes = append(c.nodes, node)
+	}
+
+	return c, nil
+}
+
+// Addrs - адреса узлов host:port в порядке запуска.
+func (c *Cluster) Addrs() []string {
+	addrs := make([]string, len(c.nodes))
+	for i, node := range c.nodes {
+		addrs[i] = node.addr
+	}
+
+	return addrs
+}
+
+// Node - узел i в порядке запуска.
+func (c *Cluster) Node(i int) *Node {
+	return c.nodes[i]
+}
+
+// Close - остановка всех узлов без ожидания запросов в обработке.
+func (c *Cluster) Close() error {
+	var errs []error
+	for _, node := range c.nodes {
+		if err := node.close(); err != nil {
+			errs = append(errs, fmt.Errorf("узел %s: %w", node.addr, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Node - один сервер кластера.
+type Node struct {
+	addr    string
+	srv     *server.Server
+	handler *crashingHandler
+
+	cancel context.CancelFunc
+	done   chan error
+
+	stopOnce  sync.Once
+	closeOnce sync.Once
+	closeErr  error
+}
+
+func startNode(cfg *config.GRPCServerConfig) (*Node, error) {
+	srv, err := server.New(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("server.New: %w", err)
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("net.Listen: %w", err)
+	}
+
+	n := &Node{
+		addr: lis.Addr().String(),
+		srv:  srv,
+		done: make(chan error, 1),
+	}
+	svc := grepsvc.New()
+	n.handler = &crashingHandler{next: grpchandlers.New(svc), crash: n.Crash}
+	pbg.RegisterGrepServiceServer(srv.GetGRPCServer(), n.handler)
+	srv.RegisterPatternCache(svc.PatternCacheStats)
+	srv.SetCapabilities(svc.Capabilities())
+
+	var ctx context.Context
+	ctx, n.cancel = context.WithCancel(context.Background())
+	go func() {
+		n.done <- srv.Serve(ctx, lis)
+	}()
+
+	return n, nil
+}
+
+// Addr - адрес узла host:port.
+func (n *Node) Addr() string {
+	return n.addr
+}
+
+// SetFaults - неисправности узла для следующих запросов, заменяют прежние.
+// Правила без Method действуют на все методы GrepService.
+func (n *Node) SetFaults(rules ...server.MethodFaults) error {
+	rules = slices.Clone(rules)
+	for i := range rules {
+		if rules[i].Method == "" {
+			rules[i].Method = grepMethods
+		}
+	}
+
+	if err := n.srv.SetFaults(rules); err != nil {
+		return fmt.Errorf("srv.SetFaults: %w", err)
+	}
+
+	return nil
+}
+
+// CrashAfter - аварийная остановка узла на запросе ProcessChunk с номером
+// call (с 1, считая уже полученные), не ответив на него; 0 - не останавливается.
+func (n *Node) CrashAfter(call int64) {
+	n.handler.crashAfter.Store(call)
+}
+
+// Chunks - сколько запросов ProcessChunk получил узел.
+func (n *Node) Chunks() int64 {
+	return n.handler.calls.Load()
+}
+
+// Crash - аварийная остановка узла: соединения рвутся, запросы
+// в обработке завершаются у клиентов ошибкой UNAVAILABLE.
+// Перезапуск не поддерживается.
+func (n *Node) Crash() {
+	n.stopOnce.Do(func() {
+		n.srv.GetGRPCServer().Stop()
+	})
+}
+
+func (n *Node) close() error {
+	n.closeOnce.Do(func() {
+		n.Crash()
+		n.cancel()
+		// узел мог упасть раньше, чем начал принимать соединения
+		if err := <-n.done; !errors.Is(err, grpc.ErrServerStopped) {
+			n.closeErr = err
+		}
+	})
+
+	return n.closeErr
+}
@@ -0,0 +1,105 @@
+package localcluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/sunr3d/quorum-grep/internal/config"
+	"github.com/sunr3d/quorum-grep/internal/server"
+	pbg "github.com/sunr3d/quorum-grep/proto/grepsvc"
+)
+
+func call(t *testing.T, addr string) (*pbg.ChunkResponse, error) {
+	t.Helper()
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	return pbg.NewGrepServiceClient(conn).ProcessChunk(context.Background(), &pbg.ChunkRequest{
+		Data:        []byte("foo\nbar"),
+		LineNumbers: []int64{1, 2},
+		Options:     &pbg.GrepOptions{Pattern: "foo"},
+	})
+}
+
+func TestFaults(t *testing.T) {
+	tests := []struct {
+		name       string
+		faults     []server.MethodFaults
+		crashAfter int64
+		expected   []int64
+		code       codes.Code
+	}{
+		{name: "без неисправностей", expected: []int64{1}},
+		{name: "ошибка", faults: []server.MethodFaults{{ErrorProbability: 1}}, code: codes.Unavailable},
+		{name: "неверный результат", faults: []server.MethodFaults{{OmitProbability: 1}}, expected: nil},
+		{name: "правило для другого метода", faults: []server.MethodFaults{{Method: "/other.Service/*", ErrorProbability: 1}}, expected: []int64{1}},
+		{name: "падение", crashAfter: 1, code: codes.Unavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := Start(1, nil)
+			require.NoError(t, err)
+			defer c.Close()
+			require.NoError(t, c.Node(0).SetFaults(tt.faults...))
+			c.Node(0).CrashAfter(tt.crashAfter)
+
+			resp, err := call(t, c.Addrs()[0])
+			if tt.code != codes.OK {
+				assert.Equal(t, tt.code, status.Code(err), "%v", err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), c.Node(0).Chunks())
+
+			var lines []int64
+			for _, m := range resp.Matches {
+				lines = append(lines, m.LineNumber)
+			}
+			assert.Equal(t, tt.expected, lines)
+		})
+	}
+}
+
+func TestDropFault(t *testing.T) {
+	// бюджет сервера меньше дедлайна клиента: запрос без ответа
+	// завершается дедлайном клиента, а не лимитом сервера
+	c, err := Start(1, &config.GRPCServerConfig{MaxChunkDuration: "20ms"})
+	require.NoError(t, err)
+	defer c.Close()
+	require.NoError(t, c.Node(0).SetFaults(server.MethodFaults{DropProbability: 1}))
+
+	conn, err := grpc.NewClient(c.Addrs()[0], grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	_, err = pbg.NewGrepServiceClient(conn).ProcessChunk(ctx, &pbg.ChunkRequest{Options: &pbg.GrepOptions{Pattern: "foo"}})
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err), "%v", err)
+}
+
+func TestCrash(t *testing.T) {
+	c, err := Start(2, nil)
+	require.NoError(t, err)
+
+	c.Node(0).Crash()
+	_, err = call(t, c.Addrs()[0])
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	_, err = call(t, c.Addrs()[1])
+	assert.NoError(t, err)
+
+	assert.NoError(t, c.Close())
+	assert.NoError(t, c.Close(), "повторный Close")
+}
@@ -0,0 +1,40 @@
+package localcluster
+
+import (
+	"context"
+	"sync/atomic"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pbg "github.com/sunr3d/quorum-grep/proto/grepsvc"
+)
+
+// grepMethods - шаблон методов GrepService для правил без Method.
+const grepMethods = "/grepsvc.GrepService/*"
+
+// crashingHandler - GrepService, который считает запросы ProcessChunk
+// и аварийно останавливает узел на запросе с номером crashAfter.
+// Остальные неисправности вносит интерсептор server (server.MethodFaults).
+type crashingHandler struct {
+	pbg.UnimplementedGrepServiceServer
+	next       pbg.GrepServiceServer
+	crash      func()
+	calls      atomic.Int64
+	crashAfter atomic.Int64
+}
+
+// ProcessChunk - обработка чанка; на запросе crashAfter узел
+// останавливается, не ответив.
+func (h *crashingHandler) ProcessChunk(ctx context.Context, req *pbg.ChunkRequest) (*pbg.ChunkResponse, error) {
+	call := h.calls.Add(1)
+
+	if n := h.crashAfter.Load(); n > 0 && call >= n {
+		// Stop ждет завершения обработчиков, поэтому не из обработчика
+		go h.crash()
+		<-ctx.Done()
+		return nil, status.Error(codes.Unavailable, "узел остановлен")
+	}
+
+	return h.next.ProcessChunk(ctx, req)
+}
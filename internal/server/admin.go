@@ -0,0 +1,256 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/wb-go/wbf/zlog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/sunr3d/quorum-grep/internal/config"
+	"github.com/sunr3d/quorum-grep/internal/grpcerr"
+	"github.com/sunr3d/quorum-grep/internal/tlsutil"
+	"github.com/sunr3d/quorum-grep/internal/version"
+	"github.com/sunr3d/quorum-grep/models"
+	pba "github.com/sunr3d/quorum-grep/proto/adminsvc"
+)
+
+var _ pba.AdminServiceServer = (*adminHandler)(nil)
+
+// errFaultsDisabled - ответ ручек неисправностей на сервере без --faults.
+var errFaultsDisabled = status.Error(codes.FailedPrecondition, "внесение неисправностей не включено")
+
+// adminGuard - права на AdminService: Drain выводит сервер из работы
+// без возврата, SetFaults ломает ответы, поэтому сервис доступен только
+// явно допущенным клиентам.
+type adminGuard struct {
+	// subjects - CN/DNS SAN клиентов mTLS с доступом к AdminService.
+	subjects []string
+}
+
+// newAdminGuard - права на AdminService, nil если сервис не регистрируется:
+// нет ни аутентификации токенами, ни ADMIN.SUBJECTS, ни ADMIN.ENABLED.
+func newAdminGuard(cfg *config.GRPCServerConfig, withAuth bool) (*adminGuard, error) {
+	if len(cfg.Admin.Subjects) > 0 && cfg.TLS.ClientCAFile == "" {
+		return nil, errors.New("ADMIN.SUBJECTS требует mTLS (TLS.CLIENT_CA_FILE)")
+	}
+	if !withAuth && len(cfg.Admin.Subjects) == 0 && !cfg.Admin.Enabled {
+		return nil, nil
+	}
+
+	return &adminGuard{subjects: cfg.Admin.Subjects}, nil
+}
+
+// unaryInterceptor - проверка прав на методы AdminService. Права токена
+// уже проверены auth: без "/adminsvc.AdminService/*" в methods токен
+// до сюда не доходит. Без токена нужен сертификат из subjects;
+// если subjects пуст, сервис включен явно через ADMIN.ENABLED.
+func (g *adminGuard) unaryInterceptor(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	if !strings.HasPrefix(info.FullMethod, adminMethodPrefix) {
+		return handler(ctx, req)
+	}
+
+	if err := g.authorize(ctx); err != nil {
+		zlog.Logger.Warn().
+			Err(err).
+			Str("method", info.FullMethod).
+			Msg("Запрос отклонен")
+		return nil, grpcerr.ToStatus(ctx, err, nil)
+	}
+
+	return handler(ctx, req)
+}
+
+func (g *adminGuard) authorize(ctx context.Context) error {
+	if _, ok := principalFromContext(ctx); ok || len(g.subjects) == 0 {
+		return nil
+	}
+
+	p, _ := peer.FromContext(ctx)
+	var tlsInfo credentials.TLSInfo
+	if p != nil {
+		tlsInfo, _ = p.AuthInfo.(credentials.TLSInfo)
+	}
+	if err := tlsutil.VerifySubject(tlsInfo.State.PeerCertificates, g.subjects); err != nil {
+		return fmt.Errorf("%w: AdminService: %w", models.ErrPermissionDenied, err)
+	}
+
+	return nil
+}
+
+// adminHandler - ручки AdminService.
+type adminHandler struct {
+	pba.UnimplementedAdminServiceServer
+	srv *Server
+}
+
+// GetInfo - версия, возможности и действующие лимиты сервера.
+func (h *adminHandler) GetInfo(context.Context, *pba.GetInfoRequest) (*pba.ServerInfo, error) {
+	s := h.srv
+	b := version.Get()
+
+	commit := b.Commit
+	if commit != "" && b.Modified {
+		commit += "-dirty"
+	}
+
+	lim := &pba.Limits{
+		MaxMessageSize:      int64(s.limits.maxMessageSize),
+		MaxResponseSize:     int64(s.limits.maxResponseSize),
+		MaxChunkDurationMs:  s.limits.maxChunkDuration.Milliseconds(),
+		MaxConcurrentChunks: int64(s.limits.scheduler.capacity),
+		MaxQueuedPerClient:  int64(s.limits.scheduler.maxQueue),
+	}
+	if s.rate != nil {
+		lim.RateLimit = s.rate.rate
+		lim.RateBurst = int64(s.rate.burst)
+	}
+
+	return &pba.ServerInfo{
+		Version:   b.Version,
+		Commit:    commit,
+		BuildTime: b.Time,
+		GoVersion: b.GoVersion,
+		Engines:   s.caps.Engines,
+		Options:   s.caps.Options,
+		Limits:    lim,
+		Tls:       s.tls,
+		Auth:      s.auth,
+		Faults:    s.faults != nil,
+	}, nil
+}
+
+// GetStats - счетчики обработанных чанков с момента запуска.
+func (h *adminHandler) GetStats(context.Context, *pba.GetStatsRequest) (*pba.ServerStats, error) {
+	s := h.srv
+	t := &s.metrics.totals
+
+	resp := &pba.ServerStats{
+		UptimeMs:     time.Since(s.started).Milliseconds(),
+		InFlight:     s.drainer.inFlight.Load(),
+		Chunks:       t.chunks.Load(),
+		FailedChunks: t.failed.Load(),
+		ScannedBytes: t.bytes.Load(),
+		ScannedLines: t.lines.Load(),
+		Matches:      t.matches.Load(),
+		Draining:     s.drainer.draining.Load(),
+	}
+	if s.cacheStats != nil {
+		cs := s.cacheStats()
+		resp.PatternCacheSize = int64(cs.Size)
+		resp.PatternCacheHits = cs.Hits
+		resp.PatternCacheMisses = cs.Misses
+	}
+
+	return resp, nil
+}
+
+// Drain - вывод сервера из работы: health переходит в NOT_SERVING,
+// новые чанки отклоняются, начатые дорабатываются. С wait ответ
+// приходит после завершения начатых чанков.
+func (h *adminHandler) Drain(ctx context.Context, req *pba.DrainRequest) (*pba.DrainResponse, error) {
+	s := h.srv
+	if !s.drainer.draining.Load() {
+		zlog.Logger.Warn().
+			Str("subject", subject(ctx)).
+			Msg("Сервер выводится из работы")
+	}
+
+	s.healthServer.Shutdown()
+	inFlight, err := s.drainer.drain(ctx, req.GetWait())
+	if err != nil {
+		return nil, status.FromContextError(err).Err()
+	}
+
+	return &pba.DrainResponse{InFlight: inFlight}, nil
+}
+
+// GetFaults - текущие неисправности.
+func (h *adminHandler) GetFaults(context.Context, *pba.GetFaultsRequest) (*pba.Faults, error) {
+	if h.srv.faults == nil {
+		return nil, errFaultsDisabled
+	}
+
+	return h.faultsResponse(), nil
+}
+
+// SetFaults - замена неисправностей на ходу, пустой список методов
+// убирает все неисправности.
+func (h *adminHandler) SetFaults(ctx context.Context, req *pba.SetFaultsRequest) (*pba.Faults, error) {
+	if h.srv.faults == nil {
+		return nil, errFaultsDisabled
+	}
+
+	f := req.GetFaults()
+	rules := make([]MethodFaults, len(f.GetMethods()))
+	for i, m := range f.GetMethods() {
+		rules[i] = MethodFaults{
+			Method:             m.Method,
+			Delay:              time.Duration(m.DelayMs) * time.Millisecond,
+			DelayProbability:   m.DelayProbability,
+			DropProbability:    m.DropProbability,
+			ErrorProbability:   m.ErrorProbability,
+			CorruptProbability: m.CorruptProbability,
+			OmitProbability:    m.OmitProbability,
+		}
+	}
+
+	if err := h.srv.faults.set(f.GetEnabled(), rules); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	zlog.Logger.Warn().
+		Bool("enabled", f.GetEnabled()).
+		Int("methods", len(rules)).
+		Str("subject", subject(ctx)).
+		Msg("Неисправности изменены")
+
+	return h.faultsResponse(), nil
+}
+
+func (h *adminHandler) faultsResponse() *pba.Faults {
+	enabled, rules := h.srv.faults.get()
+
+	resp := &pba.Faults{Enabled: enabled, Methods: make([]*pba.MethodFaults, len(rules))}
+	for i, r := range rules {
+		resp.Methods[i] = &pba.MethodFaults{
+			Method:             r.Method,
+			DelayMs:            r.Delay.Milliseconds(),
+			DelayProbability:   r.DelayProbability,
+			DropProbability:    r.DropProbability,
+			ErrorProbability:   r.ErrorProbability,
+			CorruptProbability: r.CorruptProbability,
+			OmitProbability:    r.OmitProbability,
+		}
+	}
+
+	return resp
+}
+
+// subject - субъект токена или CN сертификата клиента для журнала,
+// пусто без аутентификации.
+func subject(ctx context.Context) string {
+	if p, ok := principalFromContext(ctx); ok {
+		return p.Subject
+	}
+
+	if p, ok := peer.FromContext(ctx); ok {
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(tlsInfo.State.PeerCertificates) > 0 {
+			return tlsInfo.State.PeerCertificates[0].Subject.CommonName
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,170 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/sunr3d/quorum-grep/internal/config"
+	"github.com/sunr3d/quorum-grep/models"
+	pba "github.com/sunr3d/quorum-grep/proto/adminsvc"
+	pbg "github.com/sunr3d/quorum-grep/proto/grepsvc"
+)
+
+func TestDrainer(t *testing.T) {
+	d := &drainer{}
+	chunk := &grpc.UnaryServerInfo{FullMethod: "/grepsvc.GrepService/ProcessChunk"}
+	ok := func(context.Context, any) (any, error) { return &pbg.ChunkResponse{}, nil }
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	go func() {
+		_, _ = d.unaryInterceptor(context.Background(), nil, chunk, func(context.Context, any) (any, error) {
+			close(started)
+			<-release
+			return &pbg.ChunkResponse{}, nil
+		})
+	}()
+	<-started
+
+	n, err := d.drain(context.Background(), false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n, "начатый чанк продолжает обработку")
+
+	_, err = d.unaryInterceptor(context.Background(), nil, chunk, ok)
+	assert.Equal(t, codes.Unavailable, status.Code(err), "новые чанки отклоняются")
+
+	_, err = d.unaryInterceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: adminMethodPrefix + "GetStats"}, ok)
+	assert.NoError(t, err, "AdminService доступен")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*drainPollInterval)
+	defer cancel()
+	_, err = d.drain(ctx, true)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(release)
+	n, err = d.drain(context.Background(), true)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+func TestAdminGuard(t *testing.T) {
+	mtls := config.ServerTLSConfig{CertFile: "server.pem", KeyFile: "server.key", ClientCAFile: "ca.pem"}
+
+	tests := []struct {
+		name       string
+		cfg        config.GRPCServerConfig
+		withAuth   bool
+		registered bool
+		wantErr    bool
+	}{
+		{name: "по умолчанию не регистрируется", cfg: config.GRPCServerConfig{Faults: config.ServerFaultsConfig{Enabled: true}}},
+		{name: "mTLS без списка субъектов", cfg: config.GRPCServerConfig{TLS: mtls}},
+		{name: "токены", withAuth: true, registered: true},
+		{name: "явное включение", cfg: config.GRPCServerConfig{Admin: config.ServerAdminConfig{Enabled: true}}, registered: true},
+		{name: "субъекты mTLS", cfg: config.GRPCServerConfig{TLS: mtls, Admin: config.ServerAdminConfig{Subjects: []string{"ops"}}}, registered: true},
+		{name: "субъекты без mTLS", cfg: config.GRPCServerConfig{Admin: config.ServerAdminConfig{Subjects: []string{"ops"}}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := newAdminGuard(&tt.cfg, tt.withAuth)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.registered, g != nil)
+		})
+	}
+
+	withCN := func(cn string) context.Context {
+		state := tls.ConnectionState{PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: cn}}}}
+		return peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{State: state}})
+	}
+	drain := &grpc.UnaryServerInfo{FullMethod: adminMethodPrefix + "Drain"}
+	ok := func(context.Context, any) (any, error) { return &pba.DrainResponse{}, nil }
+
+	calls := []struct {
+		name     string
+		guard    *adminGuard
+		ctx      context.Context
+		info     *grpc.UnaryServerInfo
+		expected codes.Code
+	}{
+		{name: "сертификат из списка", guard: &adminGuard{subjects: []string{"ops"}}, ctx: withCN("ops"), info: drain, expected: codes.OK},
+		{name: "сертификат не из списка", guard: &adminGuard{subjects: []string{"ops"}}, ctx: withCN("ci"), info: drain, expected: codes.PermissionDenied},
+		{name: "без сертификата", guard: &adminGuard{subjects: []string{"ops"}}, ctx: context.Background(), info: drain, expected: codes.PermissionDenied},
+		{name: "поиск не проверяется", guard: &adminGuard{subjects: []string{"ops"}}, ctx: withCN("ci"), info: &grpc.UnaryServerInfo{FullMethod: "/grepsvc.GrepService/ProcessChunk"}, expected: codes.OK},
+		{name: "права проверены по токену", guard: &adminGuard{subjects: []string{"ops"}}, ctx: context.WithValue(context.Background(), principalKey{}, &principal{Subject: "ops"}), info: drain, expected: codes.OK},
+		{name: "явное включение", guard: &adminGuard{}, ctx: context.Background(), info: drain, expected: codes.OK},
+	}
+
+	for _, tt := range calls {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.guard.unaryInterceptor(tt.ctx, nil, tt.info, ok)
+			assert.Equal(t, tt.expected, status.Code(err))
+		})
+	}
+}
+
+func TestAdminHandler_info(t *testing.T) {
+	srv, err := New(&config.GRPCServerConfig{
+		MaxConcurrentChunks: 4,
+		MaxQueuedPerClient:  2,
+		MaxChunkDuration:    "10s",
+		RateLimit:           5,
+	})
+	require.NoError(t, err)
+	assert.NotContains(t, srv.grpcServer.GetServiceInfo(), pba.AdminService_ServiceDesc.ServiceName,
+		"без аутентификации и --admin AdminService не регистрируется")
+	srv.SetCapabilities(models.Capabilities{Engines: []string{"regexp"}, Options: []string{"count"}})
+	srv.RegisterPatternCache(func() models.PatternCacheStats {
+		return models.PatternCacheStats{Size: 3, Hits: 7, Misses: 3}
+	})
+	h := &adminHandler{srv: srv}
+
+	info, err := h.GetInfo(context.Background(), &pba.GetInfoRequest{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, info.Version)
+	assert.Equal(t, []string{"regexp"}, info.Engines)
+	assert.Equal(t, int64(4), info.Limits.MaxConcurrentChunks)
+	assert.Equal(t, int64(2), info.Limits.MaxQueuedPerClient)
+	assert.Equal(t, int64(10_000), info.Limits.MaxChunkDurationMs)
+	assert.Equal(t, 5.0, info.Limits.RateLimit)
+	assert.Equal(t, int64(5), info.Limits.RateBurst)
+	assert.False(t, info.Faults)
+
+	req := &pbg.ChunkRequest{Data: []byte("a\n"), LineNumbers: []int64{1}}
+	_, err = srv.metrics.unaryInterceptor(context.Background(), req,
+		&grpc.UnaryServerInfo{FullMethod: "/grepsvc.GrepService/ProcessChunk"},
+		func(context.Context, any) (any, error) {
+			return &pbg.ChunkResponse{Matches: []*pbg.Match{{LineNumber: 1}}}, nil
+		})
+	require.NoError(t, err)
+
+	_, err = h.Drain(context.Background(), &pba.DrainRequest{Wait: true})
+	require.NoError(t, err)
+
+	stats, err := h.GetStats(context.Background(), &pba.GetStatsRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats.Chunks)
+	assert.Equal(t, int64(2), stats.ScannedBytes)
+	assert.Equal(t, int64(1), stats.Matches)
+	assert.True(t, stats.Draining)
+	assert.Equal(t, int64(3), stats.PatternCacheSize)
+	assert.Equal(t, uint64(7), stats.PatternCacheHits)
+
+	_, err = h.GetFaults(context.Background(), &pba.GetFaultsRequest{})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err), "неисправности не включены")
+}
@@ -0,0 +1,283 @@
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"gopkg.in/yaml.v3"
+
+	"github.com/wb-go/wbf/zlog"
+
+	"github.com/sunr3d/quorum-grep/internal/config"
+	"github.com/sunr3d/quorum-grep/internal/grpcerr"
+	"github.com/sunr3d/quorum-grep/models"
+)
+
+// AuthorizationHeader - заголовок метаданных с bearer токеном.
+const AuthorizationHeader = "authorization"
+
+// DefaultMethods - методы, доступные токену без явного списка methods.
+var DefaultMethods = []string{"/grepsvc.GrepService/*"}
+
+// principal - аутентифицированный владелец токена и его права.
+type principal struct {
+	Subject string   `yaml:"subject" json:"sub"`
+	Methods []string `yaml:"methods" json:"methods"`
+}
+
+// allowed - разрешен ли вызов метода. Шаблоны методов - path.Match,
+// например "/grepsvc.GrepService/*".
+func (p *principal) allowed(method string) bool {
+	methods := p.Methods
+	if len(methods) == 0 {
+		methods = DefaultMethods
+	}
+
+	for _, pattern := range methods {
+		if ok, _ := path.Match(pattern, method); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+type principalKey struct{}
+
+// principalFromContext - владелец токена текущего запроса.
+func principalFromContext(ctx context.Context) (*principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*principal)
+	return p, ok
+}
+
+// tokenFile - формат файла статических токенов.
+type tokenFile struct {
+	Tokens []struct {
+		Token     string `yaml:"token"`
+		principal `yaml:",inline"`
+	} `yaml:"tokens"`
+}
+
+// auth - проверка bearer токенов: статических из файла и JWT (HS256).
+type auth struct {
+	// tokens - ключ sha256 от токена, чтобы время поиска не зависело
+	// от совпадающего префикса.
+	tokens map[[sha256.Size]byte]*principal
+	jwtKey []byte
+	now    func() time.Time
+}
+
+// newAuth - конструктор auth, nil если аутентификация не настроена.
+func newAuth(cfg *config.ServerAuthConfig) (*auth, error) {
+	if cfg.TokenFile == "" && cfg.JWTKeyFile == "" {
+		return nil, nil
+	}
+
+	a := &auth{
+		tokens: make(map[[sha256.Size]byte]*principal),
+		now:    time.Now,
+	}
+
+	if cfg.TokenFile != "" {
+		if err := a.loadTokens(cfg.TokenFile); err != nil {
+			return nil, fmt.Errorf("loadTokens: %w", err)
+		}
+	}
+
+	if cfg.JWTKeyFile != "" {
+		key, err := os.ReadFile(cfg.JWTKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("os.ReadFile %s: %w", cfg.JWTKeyFile, err)
+		}
+		a.jwtKey = []byte(strings.TrimSpace(string(key)))
+		if len(a.jwtKey) == 0 {
+			return nil, fmt.Errorf("пустой ключ JWT в %s", cfg.JWTKeyFile)
+		}
+	}
+
+	return a, nil
+}
+
+func (a *auth) loadTokens(file string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("os.ReadFile %s: %w", file, err)
+	}
+
+	var tf tokenFile
+	if err := yaml.Unmarshal(data, &tf); err != nil {
+		return fmt.Errorf("yaml.Unmarshal %s: %w", file, err)
+	}
+
+	for i, t := range tf.Tokens {
+		if t.Token == "" || t.Subject == "" {
+			return fmt.Errorf("%s: у токена %d не задан token или subject", file, i+1)
+		}
+		p := t.principal
+		a.tokens[sha256.Sum256([]byte(t.Token))] = &p
+	}
+
+	return nil
+}
+
+// unaryInterceptor - аутентификация и проверка прав на метод.
+func (a *auth) unaryInterceptor(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	ctx, err := a.authorize(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+// streamInterceptor - то же для потоковых методов.
+func (a *auth) streamInterceptor(
+	srv any,
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	ctx, err := a.authorize(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, &authStream{ServerStream: ss, ctx: ctx})
+}
+
+type authStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authStream) Context() context.Context {
+	return s.ctx
+}
+
+// authorize - проверка токена из метаданных; health-check доступен без токена.
+// Владелец токена кладется в контекст запроса.
+func (a *auth) authorize(ctx context.Context, method string) (context.Context, error) {
+	if strings.HasPrefix(method, healthMethodPrefix) {
+		return ctx, nil
+	}
+
+	p, err := a.authenticate(ctx)
+	if err == nil && !p.allowed(method) {
+		err = fmt.Errorf("%w: %q не может вызывать %s", models.ErrPermissionDenied, p.Subject, method)
+	}
+	if err != nil {
+		zlog.Logger.Warn().
+			Err(err).
+			Str("method", method).
+			Msg("Запрос отклонен")
+		return nil, grpcerr.ToStatus(ctx, err, nil)
+	}
+
+	return context.WithValue(ctx, principalKey{}, p), nil
+}
+
+func (a *auth) authenticate(ctx context.Context) (*principal, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(AuthorizationHeader)
+	if len(values) == 0 {
+		return nil, fmt.Errorf("%w: нет заголовка %s", models.ErrUnauthenticated, AuthorizationHeader)
+	}
+
+	token, ok := strings.CutPrefix(values[0], "Bearer ")
+	if !ok || token == "" {
+		return nil, fmt.Errorf("%w: ожидается Bearer токен", models.ErrUnauthenticated)
+	}
+
+	if p, ok := a.tokens[sha256.Sum256([]byte(token))]; ok {
+		return p, nil
+	}
+
+	if a.jwtKey != nil && strings.Count(token, ".") == 2 {
+		p, err := a.verifyJWT(token)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", models.ErrUnauthenticated, err)
+		}
+		return p, nil
+	}
+
+	return nil, fmt.Errorf("%w: неизвестный токен", models.ErrUnauthenticated)
+}
+
+var errInvalidJWT = errors.New("некорректный JWT")
+
+// jwtClaims - поддерживаемые поля JWT; права передаются в поле methods.
+type jwtClaims struct {
+	principal
+	ExpiresAt int64 `json:"exp"`
+	NotBefore int64 `json:"nbf"`
+}
+
+// verifyJWT - проверка подписи HS256 и сроков действия JWT.
+func (a *auth) verifyJWT(token string) (*principal, error) {
+	parts := strings.Split(token, ".")
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: заголовок: %w", errInvalidJWT, err)
+	}
+	// alg берется из токена только для проверки: принимается исключительно HS256
+	if header.Alg != "HS256" {
+		return nil, fmt.Errorf("%w: алгоритм %q не поддерживается", errInvalidJWT, header.Alg)
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: подпись: %w", errInvalidJWT, err)
+	}
+	mac := hmac.New(sha256.New, a.jwtKey)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return nil, fmt.Errorf("%w: неверная подпись", errInvalidJWT)
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: claims: %w", errInvalidJWT, err)
+	}
+
+	now := a.now().Unix()
+	switch {
+	case claims.Subject == "":
+		return nil, fmt.Errorf("%w: не задан sub", errInvalidJWT)
+	case claims.ExpiresAt == 0:
+		return nil, fmt.Errorf("%w: не задан exp", errInvalidJWT)
+	case now >= claims.ExpiresAt:
+		return nil, fmt.Errorf("%w: срок действия истек", errInvalidJWT)
+	case claims.NotBefore != 0 && now < claims.NotBefore:
+		return nil, fmt.Errorf("%w: токен еще не действует", errInvalidJWT)
+	}
+
+	return &claims.principal, nil
+}
+
+func decodeSegment(seg string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return fmt.Errorf("base64: %w", err)
+	}
+
+	return json.Unmarshal(data, v)
+}
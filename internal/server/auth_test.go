@@ -0,0 +1,176 @@
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/sunr3d/quorum-grep/internal/config"
+)
+
+const testTokens = `
+tokens:
+  - token: grep-secret
+    subject: ci
+  - token: admin-secret
+    subject: ops
+    methods: ["/grepsvc.AdminService/*", "/grepsvc.GrepService/*"]
+  - token: admin-only
+    subject: monitoring
+    methods: ["/grepsvc.AdminService/GetStats"]
+`
+
+func signJWT(t *testing.T, key string, header, claims map[string]any) string {
+	t.Helper()
+
+	enc := func(v any) string {
+		data, err := json.Marshal(v)
+		require.NoError(t, err)
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+
+	unsigned := enc(header) + "." + enc(claims)
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(unsigned))
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestAuth(t *testing.T) {
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "tokens.yaml")
+	keyFile := filepath.Join(dir, "jwt.key")
+	require.NoError(t, os.WriteFile(tokenFile, []byte(testTokens), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, []byte("jwt-key\n"), 0o600))
+
+	a, err := newAuth(&config.ServerAuthConfig{TokenFile: tokenFile, JWTKeyFile: keyFile})
+	require.NoError(t, err)
+	now := time.Unix(1_700_000_000, 0)
+	a.now = func() time.Time { return now }
+
+	hs256 := map[string]any{"alg": "HS256", "typ": "JWT"}
+	validJWT := signJWT(t, "jwt-key", hs256, map[string]any{"sub": "bot", "exp": now.Add(time.Hour).Unix()})
+
+	const grepMethod = "/grepsvc.GrepService/ProcessChunk"
+	const statsMethod = "/grepsvc.AdminService/GetStats"
+
+	tests := []struct {
+		name     string
+		header   string
+		method   string
+		expected codes.Code
+		subject  string
+	}{
+		{name: "статический токен", header: "Bearer grep-secret", method: grepMethod, expected: codes.OK, subject: "ci"},
+		{name: "метод вне прав по умолчанию", header: "Bearer grep-secret", method: statsMethod, expected: codes.PermissionDenied},
+		{name: "явный список методов", header: "Bearer admin-secret", method: statsMethod, expected: codes.OK, subject: "ops"},
+		{name: "явный список без grep", header: "Bearer admin-only", method: grepMethod, expected: codes.PermissionDenied},
+		{name: "неизвестный токен", header: "Bearer nope", method: grepMethod, expected: codes.Unauthenticated},
+		{name: "без токена", method: grepMethod, expected: codes.Unauthenticated},
+		{name: "не bearer", header: "Basic Z3JlcA==", method: grepMethod, expected: codes.Unauthenticated},
+		{name: "health без токена", method: healthMethodPrefix + "Check", expected: codes.OK},
+		{name: "JWT", header: "Bearer " + validJWT, method: grepMethod, expected: codes.OK, subject: "bot"},
+		{
+			name:     "JWT с правами",
+			header:   "Bearer " + signJWT(t, "jwt-key", hs256, map[string]any{"sub": "bot", "exp": now.Add(time.Hour).Unix(), "methods": []string{statsMethod}}),
+			method:   grepMethod,
+			expected: codes.PermissionDenied,
+		},
+		{
+			name:     "JWT истек",
+			header:   "Bearer " + signJWT(t, "jwt-key", hs256, map[string]any{"sub": "bot", "exp": now.Add(-time.Second).Unix()}),
+			method:   grepMethod,
+			expected: codes.Unauthenticated,
+		},
+		{
+			name:     "JWT без exp",
+			header:   "Bearer " + signJWT(t, "jwt-key", hs256, map[string]any{"sub": "bot"}),
+			method:   grepMethod,
+			expected: codes.Unauthenticated,
+		},
+		{
+			name:     "JWT еще не действует",
+			header:   "Bearer " + signJWT(t, "jwt-key", hs256, map[string]any{"sub": "bot", "exp": now.Add(2 * time.Hour).Unix(), "nbf": now.Add(time.Hour).Unix()}),
+			method:   grepMethod,
+			expected: codes.Unauthenticated,
+		},
+		{
+			name:     "JWT чужой ключ",
+			header:   "Bearer " + signJWT(t, "other-key", hs256, map[string]any{"sub": "bot", "exp": now.Add(time.Hour).Unix()}),
+			method:   grepMethod,
+			expected: codes.Unauthenticated,
+		},
+		{
+			name:     "JWT alg none",
+			header:   "Bearer " + signJWT(t, "jwt-key", map[string]any{"alg": "none"}, map[string]any{"sub": "bot", "exp": now.Add(time.Hour).Unix()}),
+			method:   grepMethod,
+			expected: codes.Unauthenticated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.header != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(AuthorizationHeader, tt.header))
+			}
+
+			var gotClient string
+			_, err := a.unaryInterceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method},
+				func(ctx context.Context, _ any) (any, error) {
+					gotClient = clientID(ctx)
+					return nil, nil
+				})
+
+			require.Equal(t, tt.expected, status.Code(err), "%v", err)
+			if tt.subject != "" {
+				assert.Equal(t, "sub:"+tt.subject, gotClient)
+			}
+		})
+	}
+}
+
+func TestNewAuth(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		file := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(file, []byte(data), 0o600))
+		return file
+	}
+
+	tests := []struct {
+		name    string
+		cfg     config.ServerAuthConfig
+		wantErr bool
+	}{
+		{name: "выключена", cfg: config.ServerAuthConfig{}},
+		{name: "нет файла токенов", cfg: config.ServerAuthConfig{TokenFile: filepath.Join(dir, "missing.yaml")}, wantErr: true},
+		{name: "токен без subject", cfg: config.ServerAuthConfig{TokenFile: write("nosub.yaml", "tokens:\n  - token: x\n")}, wantErr: true},
+		{name: "пустой ключ JWT", cfg: config.ServerAuthConfig{JWTKeyFile: write("empty.key", "\n")}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := newAuth(&tt.cfg)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Nil(t, a)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// drainPollInterval - как часто Drain с wait проверяет, доработали ли чанки.
+const drainPollInterval = 50 * time.Millisecond
+
+// drainer - учет запросов в обработке и вывод сервера из работы.
+type drainer struct {
+	draining atomic.Bool
+	inFlight atomic.Int64
+}
+
+// unaryInterceptor - после Drain новые запросы отклоняются с UNAVAILABLE:
+// клиент повторит чанк на другом сервере.
+func (d *drainer) unaryInterceptor(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	if controlMethod(info.FullMethod) {
+		return handler(ctx, req)
+	}
+
+	d.inFlight.Add(1)
+	defer d.inFlight.Add(-1)
+
+	// проверка после учета: Drain с wait не пропустит запрос,
+	// пришедший одновременно с ним
+	if d.draining.Load() {
+		return nil, status.Error(codes.Unavailable, "сервер выводится из работы")
+	}
+
+	return handler(ctx, req)
+}
+
+// drain - перевод в режим вывода из работы; при wait ожидает
+// завершения начатых запросов или отмены ctx.
+func (d *drainer) drain(ctx context.Context, wait bool) (int64, error) {
+	d.draining.Store(true)
+	if !wait {
+		return d.inFlight.Load(), nil
+	}
+
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if n := d.inFlight.Load(); n == 0 {
+			return 0, nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return d.inFlight.Load(), ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/sunr3d/quorum-grep/models"
+)
+
+// fairScheduler - раздача слотов обработки чанков между клиентами.
+// Пока свободных слотов нет, запросы ждут в очереди своего клиента,
+// освободившийся слот передается очередям по кругу, поэтому клиент
+// с большим поиском не вытесняет остальных.
+type fairScheduler struct {
+	mu       sync.Mutex
+	free     int
+	capacity int
+	maxQueue int
+	queues   map[string][]*waiter
+	ring     []string // клиенты с непустой очередью, порядок обслуживания
+}
+
+type waiter struct {
+	ready   chan struct{}
+	granted bool
+}
+
+// newFairScheduler - конструктор fairScheduler.
+func newFairScheduler(slots, maxQueue int) *fairScheduler {
+	return &fairScheduler{
+		free:     slots,
+		capacity: slots,
+		maxQueue: maxQueue,
+		queues:   make(map[string][]*waiter),
+	}
+}
+
+// acquire - занимает слот для клиента, при необходимости ожидая в очереди.
+// Если очередь клиента заполнена, возвращает ErrOverloaded.
+func (s *fairScheduler) acquire(ctx context.Context, client string) error {
+	s.mu.Lock()
+	if s.free > 0 && len(s.ring) == 0 {
+		s.free--
+		s.mu.Unlock()
+		return nil
+	}
+
+	if len(s.queues[client]) >= s.maxQueue {
+		s.mu.Unlock()
+		return fmt.Errorf("%w: заняты все %d слотов, очередь клиента заполнена", models.ErrOverloaded, s.capacity)
+	}
+
+	w := &waiter{ready: make(chan struct{})}
+	if len(s.queues[client]) == 0 {
+		s.ring = append(s.ring, client)
+	}
+	s.queues[client] = append(s.queues[client], w)
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if w.granted {
+			// слот уже передан, возвращаем его следующему
+			s.releaseLocked()
+		} else {
+			s.removeLocked(client, w)
+		}
+
+		return fmt.Errorf("%w: %w", models.ErrCancelled, ctx.Err())
+	}
+}
+
+// release - освобождает слот и передает его следующему клиенту по кругу.
+func (s *fairScheduler) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.releaseLocked()
+}
+
+func (s *fairScheduler) releaseLocked() {
+	if len(s.ring) == 0 {
+		s.free++
+		return
+	}
+
+	client := s.ring[0]
+	queue := s.queues[client]
+	w := queue[0]
+
+	if len(queue) == 1 {
+		delete(s.queues, client)
+		s.ring = s.ring[1:]
+	} else {
+		s.queues[client] = queue[1:]
+		// клиент уходит в конец круга
+		s.ring = append(s.ring[1:], client)
+	}
+
+	w.granted = true
+	close(w.ready)
+}
+
+func (s *fairScheduler) removeLocked(client string, w *waiter) {
+	queue := s.queues[client]
+	for i, qw := range queue {
+		if qw == w {
+			queue = append(queue[:i], queue[i+1:]...)
+			break
+		}
+	}
+
+	if len(queue) > 0 {
+		s.queues[client] = queue
+		return
+	}
+
+	delete(s.queues, client)
+	for i, c := range s.ring {
+		if c == client {
+			s.ring = append(s.ring[:i], s.ring[i+1:]...)
+			break
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gopkg.in/yaml.v3"
+
+	"github.com/wb-go/wbf/zlog"
+
+	"github.com/sunr3d/quorum-grep/internal/config"
+	"github.com/sunr3d/quorum-grep/internal/ctxutil"
+	pbg "github.com/sunr3d/quorum-grep/proto/grepsvc"
+)
+
+// adminMethodPrefix - методы AdminService не ограничиваются лимитами
+// и неисправностями, чтобы их можно было выключить на перегруженном сервере.
+const adminMethodPrefix = "/adminsvc.AdminService/"
+
+// controlMethod - health-check или AdminService.
+func controlMethod(method string) bool {
+	return strings.HasPrefix(method, healthMethodPrefix) || strings.HasPrefix(method, adminMethodPrefix)
+}
+
+// MethodFaults - неисправности методов, подходящих под шаблон Method
+// (path.Match, как права токенов). Вероятности от 0 до 1 проверяются
+// для каждого запроса независимо.
+type MethodFaults struct {
+	Method string `yaml:"method"`
+	// Delay - задержка перед обработкой с вероятностью DelayProbability.
+	Delay            time.Duration `yaml:"delay"`
+	DelayProbability float64       `yaml:"delay_probability"`
+	// DropProbability - запрос остается без ответа до дедлайна клиента.
+	DropProbability float64 `yaml:"drop_probability"`
+	// ErrorProbability - ответ UNAVAILABLE без обработки.
+	ErrorProbability float64 `yaml:"error_probability"`
+	// CorruptProbability - содержимое одного совпадения в ответе искажается.
+	CorruptProbability float64 `yaml:"corrupt_probability"`
+	// OmitProbability - одно совпадение пропадает из ответа.
+	OmitProbability float64 `yaml:"omit_probability"`
+}
+
+func (m MethodFaults) validate() error {
+	if _, err := path.Match(m.Method, ""); err != nil || m.Method == "" {
+		return fmt.Errorf("некорректный шаблон метода %q", m.Method)
+	}
+	if m.Delay < 0 {
+		return fmt.Errorf("%s: отрицательная задержка %s", m.Method, m.Delay)
+	}
+
+	for name, p := range map[string]float64{
+		"delay_probability":   m.DelayProbability,
+		"drop_probability":    m.DropProbability,
+		"error_probability":   m.ErrorProbability,
+		"corrupt_probability": m.CorruptProbability,
+		"omit_probability":    m.OmitProbability,
+	} {
+		if p < 0 || p > 1 {
+			return fmt.Errorf("%s: %s должна быть от 0 до 1, указана %v", m.Method, name, p)
+		}
+	}
+
+	return nil
+}
+
+// faultsFile - формат файла неисправностей.
+type faultsFile struct {
+	Methods []MethodFaults `yaml:"methods"`
+}
+
+// faultInjector - намеренные неисправности сервера для проверки кворума
+// и повторов клиента. Для метода действует первое подходящее правило.
+// Правила и включение меняются на ходу через AdminService.
+type faultInjector struct {
+	rand func() float64
+
+	mu      sync.RWMutex
+	enabled bool
+	rules   []MethodFaults
+}
+
+// newFaultInjector - конструктор faultInjector, nil если неисправности
+// не включены.
+func newFaultInjector(cfg *config.ServerFaultsConfig) (*faultInjector, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	f := &faultInjector{rand: rand.Float64, enabled: true}
+	if cfg.File == "" {
+		return f, nil
+	}
+
+	data, err := os.ReadFile(cfg.File)
+	if err != nil {
+		return nil, fmt.Errorf("os.ReadFile %s: %w", cfg.File, err)
+	}
+	var ff faultsFile
+	if err := yaml.Unmarshal(data, &ff); err != nil {
+		return nil, fmt.Errorf("yaml.Unmarshal %s: %w", cfg.File, err)
+	}
+	if err := f.set(true, ff.Methods); err != nil {
+		return nil, fmt.Errorf("%s: %w", cfg.File, err)
+	}
+
+	return f, nil
+}
+
+// set - замена правил с проверкой.
+func (f *faultInjector) set(enabled bool, rules []MethodFaults) error {
+	for _, r := range rules {
+		if err := r.validate(); err != nil {
+			return err
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.enabled = enabled
+	f.rules = slices.Clone(rules)
+
+	return nil
+}
+
+// get - текущие правила.
+func (f *faultInjector) get() (bool, []MethodFaults) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.enabled, slices.Clone(f.rules)
+}
+
+// rule - правило для метода.
+func (f *faultInjector) rule(method string) (MethodFaults, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if !f.enabled {
+		return MethodFaults{}, false
+	}
+	for _, r := range f.rules {
+		if ok, _ := path.Match(r.Method, method); ok {
+			return r, true
+		}
+	}
+
+	return MethodFaults{}, false
+}
+
+// unaryInterceptor - внесение неисправностей по правилу метода.
+// Искажаются и теряются только совпадения в ответах ProcessChunk.
+func (f *faultInjector) unaryInterceptor(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	if controlMethod(info.FullMethod) {
+		return handler(ctx, req)
+	}
+
+	r, ok := f.rule(info.FullMethod)
+	if !ok {
+		return handler(ctx, req)
+	}
+
+	if r.Delay > 0 && f.rand() < r.DelayProbability {
+		f.log(info.FullMethod, "delay")
+		if err := ctxutil.Sleep(ctx, r.Delay); err != nil {
+			return nil, status.FromContextError(err).Err()
+		}
+	}
+	if f.rand() < r.DropProbability {
+		f.log(info.FullMethod, "drop")
+		<-ctx.Done()
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+	if f.rand() < r.ErrorProbability {
+		f.log(info.FullMethod, "error")
+		return nil, status.Error(codes.Unavailable, "внесенная неисправность")
+	}
+
+	resp, err := handler(ctx, req)
+	chunk, ok := resp.(*pbg.ChunkResponse)
+	if err != nil || !ok || len(chunk.Matches) == 0 {
+		return resp, err
+	}
+
+	if f.rand() < r.CorruptProbability {
+		f.log(info.FullMethod, "corrupt")
+		i := rand.IntN(len(chunk.Matches))
+		// Content может ссылаться на данные запроса, поэтому не меняется на месте
+		chunk.Matches[i] = &pbg.Match{
+			Content:    append([]byte("corrupted: "), chunk.Matches[i].Content...),
+			LineNumber: chunk.Matches[i].LineNumber,
+		}
+	}
+	if f.rand() < r.OmitProbability {
+		f.log(info.FullMethod, "omit")
+		i := rand.IntN(len(chunk.Matches))
+		chunk.Matches = slices.Delete(chunk.Matches, i, i+1)
+		chunk.MatchCount = int64(len(chunk.Matches))
+	}
+
+	return chunk, nil
+}
+
+func (f *faultInjector) log(method, fault string) {
+	zlog.Logger.Warn().
+		Str("method", method).
+		Str("fault", fault).
+		Msg("Внесена неисправность")
+}
@@ -0,0 +1,173 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/sunr3d/quorum-grep/internal/config"
+	pba "github.com/sunr3d/quorum-grep/proto/adminsvc"
+	pbg "github.com/sunr3d/quorum-grep/proto/grepsvc"
+)
+
+func TestFaultInjector_unaryInterceptor(t *testing.T) {
+	chunkMethod := "/grepsvc.GrepService/ProcessChunk"
+	handler := func(context.Context, any) (any, error) {
+		return &pbg.ChunkResponse{
+			Matches:    []*pbg.Match{{Content: []byte("foo"), LineNumber: 1}, {Content: []byte("foo"), LineNumber: 3}},
+			MatchCount: 2,
+		}, nil
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		disabled bool
+		rule     MethodFaults
+		code     codes.Code
+		check    func(t *testing.T, resp *pbg.ChunkResponse)
+	}{
+		{
+			name:   "без неисправностей",
+			method: chunkMethod,
+			rule:   MethodFaults{Method: "/grepsvc.GrepService/*"},
+			check: func(t *testing.T, resp *pbg.ChunkResponse) {
+				assert.Len(t, resp.Matches, 2)
+			},
+		},
+		{name: "ошибка", method: chunkMethod, rule: MethodFaults{Method: "/*/*", ErrorProbability: 1}, code: codes.Unavailable},
+		{name: "без ответа", method: chunkMethod, rule: MethodFaults{Method: "/*/*", DropProbability: 1}, code: codes.DeadlineExceeded},
+		{
+			name:   "искажение",
+			method: chunkMethod,
+			rule:   MethodFaults{Method: chunkMethod, CorruptProbability: 1},
+			check: func(t *testing.T, resp *pbg.ChunkResponse) {
+				corrupted := 0
+				for _, m := range resp.Matches {
+					if string(m.Content) != "foo" {
+						corrupted++
+					}
+				}
+				assert.Equal(t, 1, corrupted)
+			},
+		},
+		{
+			name:   "пропуск совпадения",
+			method: chunkMethod,
+			rule:   MethodFaults{Method: chunkMethod, OmitProbability: 1},
+			check: func(t *testing.T, resp *pbg.ChunkResponse) {
+				assert.Len(t, resp.Matches, 1)
+				assert.Equal(t, int64(1), resp.MatchCount)
+			},
+		},
+		{name: "другой метод", method: "/other.Service/Call", rule: MethodFaults{Method: chunkMethod, ErrorProbability: 1}},
+		{name: "AdminService без неисправностей", method: adminMethodPrefix + "SetFaults", rule: MethodFaults{Method: "/*/*", ErrorProbability: 1}},
+		{name: "выключены", method: chunkMethod, disabled: true, rule: MethodFaults{Method: "/*/*", ErrorProbability: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &faultInjector{rand: func() float64 { return 0 }}
+			require.NoError(t, f.set(!tt.disabled, []MethodFaults{tt.rule}))
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			resp, err := f.unaryInterceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			assert.Equal(t, tt.code, status.Code(err), "%v", err)
+			if tt.check != nil {
+				tt.check(t, resp.(*pbg.ChunkResponse))
+			}
+		})
+	}
+}
+
+func TestFaultInjector_delay(t *testing.T) {
+	f := &faultInjector{rand: func() float64 { return 0.5 }}
+	require.NoError(t, f.set(true, []MethodFaults{
+		{Method: "/*/*", Delay: 30 * time.Millisecond, DelayProbability: 0.6, ErrorProbability: 0.4},
+	}))
+
+	start := time.Now()
+	_, err := f.unaryInterceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/a.B/C"},
+		func(context.Context, any) (any, error) { return &pbg.ChunkResponse{}, nil })
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+}
+
+func TestNewFaultInjector(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		file := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(file, []byte(data), 0o600))
+		return file
+	}
+
+	valid := write("valid.yaml", `
+methods:
+  - method: /grepsvc.GrepService/*
+    delay: 200ms
+    delay_probability: 0.1
+    omit_probability: 0.05
+`)
+	invalid := write("invalid.yaml", `
+methods:
+  - method: "/*/*"
+    error_probability: 2
+`)
+
+	f, err := newFaultInjector(&config.ServerFaultsConfig{})
+	require.NoError(t, err)
+	assert.Nil(t, f, "выключены по умолчанию")
+
+	f, err = newFaultInjector(&config.ServerFaultsConfig{Enabled: true, File: valid})
+	require.NoError(t, err)
+	enabled, rules := f.get()
+	assert.True(t, enabled)
+	assert.Equal(t, []MethodFaults{{
+		Method:           "/grepsvc.GrepService/*",
+		Delay:            200 * time.Millisecond,
+		DelayProbability: 0.1,
+		OmitProbability:  0.05,
+	}}, rules)
+
+	_, err = newFaultInjector(&config.ServerFaultsConfig{Enabled: true, File: invalid})
+	assert.Error(t, err)
+}
+
+func TestAdminHandler_SetFaults(t *testing.T) {
+	f, err := newFaultInjector(&config.ServerFaultsConfig{Enabled: true})
+	require.NoError(t, err)
+	h := &adminHandler{srv: &Server{faults: f}}
+
+	want := &pba.Faults{Enabled: true, Methods: []*pba.MethodFaults{
+		{Method: "/grepsvc.GrepService/ProcessChunk", DelayMs: 100, DelayProbability: 0.5, ErrorProbability: 0.1},
+	}}
+	resp, err := h.SetFaults(context.Background(), &pba.SetFaultsRequest{Faults: want})
+	require.NoError(t, err)
+	assert.Equal(t, want.Methods[0].DelayMs, resp.Methods[0].DelayMs)
+
+	got, err := h.GetFaults(context.Background(), &pba.GetFaultsRequest{})
+	require.NoError(t, err)
+	assert.True(t, got.Enabled)
+	assert.Equal(t, want.Methods[0].ErrorProbability, got.Methods[0].ErrorProbability)
+
+	_, err = h.SetFaults(context.Background(), &pba.SetFaultsRequest{Faults: &pba.Faults{
+		Enabled: true,
+		Methods: []*pba.MethodFaults{{Method: "[", ErrorProbability: 0.1}},
+	}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// некорректные правила не применяются
+	got, err = h.GetFaults(context.Background(), &pba.GetFaultsRequest{})
+	require.NoError(t, err)
+	assert.Len(t, got.Methods, 1)
+}
@@ -0,0 +1,55 @@
+package server
+
+import (
+	"context"
+	"net"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// ClientIDHeader - заголовок метаданных, которым клиент представляется серверу.
+const ClientIDHeader = "x-client-id"
+
+// clientID - идентификатор клиента для лимитов и справедливой очереди.
+// Приоритет: CN сертификата клиента (mTLS), владелец токена, IP-адрес клиента.
+// Заголовок x-client-id клиент выбирает сам и может менять на каждом
+// запросе, поэтому в идентификатор он не входит: иначе смена заголовка
+// давала бы новую корзину лимита и новую очередь. Заголовок - только
+// для журнала (headerID).
+func clientID(ctx context.Context) string {
+	p, hasPeer := peer.FromContext(ctx)
+
+	if hasPeer {
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			if certs := tlsInfo.State.PeerCertificates; len(certs) > 0 && certs[0].Subject.CommonName != "" {
+				return "cn:" + certs[0].Subject.CommonName
+			}
+		}
+	}
+
+	if owner, ok := principalFromContext(ctx); ok {
+		return "sub:" + owner.Subject
+	}
+
+	if hasPeer && p.Addr != nil {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			return "ip:" + host
+		}
+		return "ip:" + p.Addr.String()
+	}
+
+	return "unknown"
+}
+
+// headerID - заголовок x-client-id для журнала, пусто если не передан.
+func headerID(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(ClientIDHeader); len(ids) > 0 {
+			return ids[0]
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/sunr3d/quorum-grep/internal/config"
+	"github.com/sunr3d/quorum-grep/models"
+	pbg "github.com/sunr3d/quorum-grep/proto/grepsvc"
+)
+
+func TestClientID(t *testing.T) {
+	addr := &net.TCPAddr{IP: net.ParseIP("10.0.0.7"), Port: 40000}
+
+	tests := []struct {
+		name     string
+		header   string
+		owner    *principal
+		expected string
+	}{
+		{name: "IP-адрес", expected: "ip:10.0.0.7"},
+		{name: "заголовок без аутентификации не учитывается", header: "alice@host", expected: "ip:10.0.0.7"},
+		{name: "владелец токена", owner: &principal{Subject: "ci"}, expected: "sub:ci"},
+		{name: "заголовок при аутентификации не учитывается", header: "alice@host", owner: &principal{Subject: "ci"}, expected: "sub:ci"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: addr})
+			if tt.header != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(ClientIDHeader, tt.header))
+			}
+			if tt.owner != nil {
+				ctx = context.WithValue(ctx, principalKey{}, tt.owner)
+			}
+
+			assert.Equal(t, tt.expected, clientID(ctx))
+			assert.Equal(t, tt.header, headerID(ctx))
+		})
+	}
+}
+
+func TestRateLimiter_rotatingHeader(t *testing.T) {
+	rl := newRateLimiter(&config.GRPCServerConfig{RateLimit: 1, RateBurst: 2})
+	require.NotNil(t, rl)
+	now := time.Unix(0, 0)
+	rl.now = func() time.Time { return now }
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/grepsvc.GrepService/ProcessChunk"}
+	ok := func(context.Context, any) (any, error) { return &pbg.ChunkResponse{}, nil }
+	owner := &principal{Subject: "ci"}
+
+	var err error
+	for i := range 3 {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(ClientIDHeader, fmt.Sprintf("user-%d", i)))
+		ctx = context.WithValue(ctx, principalKey{}, owner)
+		_, err = rl.unaryInterceptor(ctx, nil, info, ok)
+	}
+
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err), "смена x-client-id не дает новой корзины")
+	assert.Equal(t, models.ReasonRateLimited, reasonOf(t, err))
+}
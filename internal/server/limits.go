@@ -0,0 +1,127 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/wb-go/wbf/zlog"
+
+	"github.com/sunr3d/quorum-grep/internal/config"
+	"github.com/sunr3d/quorum-grep/internal/grpcerr"
+	"github.com/sunr3d/quorum-grep/models"
+)
+
+// Значения лимитов по умолчанию, если они не заданы в GRPCServerConfig.
+const (
+	DefaultMaxMessageSize      = 64 << 20
+	DefaultMaxConcurrentChunks = 64
+	DefaultMaxChunkDuration    = 30 * time.Second
+	DefaultMaxResponseSize     = 64 << 20
+	DefaultMaxQueuedPerClient  = 16
+)
+
+// healthMethodPrefix - методы health-check не ограничиваются лимитами.
+const healthMethodPrefix = "/grpc.health.v1.Health/"
+
+// limits - лимиты ресурсов на обработку чанков.
+type limits struct {
+	maxMessageSize   int
+	maxChunkDuration time.Duration
+	maxResponseSize  int
+	scheduler        *fairScheduler
+}
+
+// newLimits - конструктор limits, незаданные значения заменяются значениями по умолчанию.
+func newLimits(cfg *config.GRPCServerConfig) *limits {
+	l := &limits{
+		maxMessageSize:   cfg.MaxMessageSize,
+		maxChunkDuration: DefaultMaxChunkDuration,
+		maxResponseSize:  cfg.MaxResponseSize,
+	}
+
+	if l.maxMessageSize <= 0 {
+		l.maxMessageSize = DefaultMaxMessageSize
+	}
+	if l.maxResponseSize <= 0 {
+		l.maxResponseSize = DefaultMaxResponseSize
+	}
+	if d, err := time.ParseDuration(cfg.MaxChunkDuration); err == nil && d > 0 {
+		l.maxChunkDuration = d
+	}
+
+	maxConcurrent := cfg.MaxConcurrentChunks
+	if maxConcurrent <= 0 {
+		maxConcurrent = DefaultMaxConcurrentChunks
+	}
+	maxQueued := cfg.MaxQueuedPerClient
+	if maxQueued <= 0 {
+		maxQueued = DefaultMaxQueuedPerClient
+	}
+	l.scheduler = newFairScheduler(maxConcurrent, maxQueued)
+
+	return l
+}
+
+// serverOptions - опции gRPC сервера для ограничения размера сообщений.
+// Лимит на отправку чуть больше maxResponseSize, чтобы превышение
+// отлавливал интерсептор и возвращал понятную ошибку.
+func (l *limits) serverOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(l.maxMessageSize),
+		grpc.MaxSendMsgSize(l.maxResponseSize + 1<<20),
+	}
+}
+
+// unaryInterceptor - admission control и лимиты на обработку одного запроса:
+// число одновременных чанков (со справедливой очередью по клиентам),
+// бюджет времени и размер ответа.
+func (l *limits) unaryInterceptor(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	if controlMethod(info.FullMethod) {
+		return handler(ctx, req)
+	}
+
+	client := clientID(ctx)
+	if err := l.scheduler.acquire(ctx, client); err != nil {
+		zlog.Logger.Warn().
+			Err(err).
+			Str("method", info.FullMethod).
+			Str("client", client).
+			Str("client_id", headerID(ctx)).
+			Msg("Запрос не допущен к обработке")
+		return nil, grpcerr.ToStatus(ctx, err, nil)
+	}
+	defer l.scheduler.release()
+
+	budgetCtx, cancel := context.WithTimeout(ctx, l.maxChunkDuration)
+	defer cancel()
+
+	resp, err := handler(budgetCtx, req)
+
+	// истек бюджет сервера, а не дедлайн клиента; это не свойство чанка,
+	// как размер ответа, а нехватка ресурсов сервера: клиент повторит
+	// чанк на другом сервере
+	if errors.Is(budgetCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+		return nil, grpcerr.ToStatus(ctx, fmt.Errorf("%w: время обработки чанка больше %s", models.ErrOverloaded, l.maxChunkDuration), nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if msg, ok := resp.(proto.Message); ok {
+		if size := proto.Size(msg); size > l.maxResponseSize {
+			return nil, grpcerr.ToStatus(ctx, fmt.Errorf("%w: размер ответа %d байт больше %d", models.ErrLimitExceeded, size, l.maxResponseSize), nil)
+		}
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,203 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/sunr3d/quorum-grep/internal/config"
+	"github.com/sunr3d/quorum-grep/models"
+	pbg "github.com/sunr3d/quorum-grep/proto/grepsvc"
+)
+
+func reasonOf(t *testing.T, err error) string {
+	t.Helper()
+
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok {
+			return info.Reason
+		}
+	}
+
+	return ""
+}
+
+func TestLimits_unaryInterceptor(t *testing.T) {
+	chunkInfo := &grpc.UnaryServerInfo{FullMethod: "/grepsvc.GrepService/ProcessChunk"}
+	ok := func(context.Context, any) (any, error) { return &pbg.ChunkResponse{}, nil }
+
+	t.Run("очередь клиента по умолчанию", func(t *testing.T) {
+		l := newLimits(&config.GRPCServerConfig{})
+		assert.Equal(t, DefaultMaxConcurrentChunks, l.scheduler.capacity)
+		assert.Equal(t, DefaultMaxQueuedPerClient, l.scheduler.maxQueue)
+	})
+
+	t.Run("превышение числа одновременных чанков", func(t *testing.T) {
+		l := newLimits(&config.GRPCServerConfig{MaxConcurrentChunks: 1, MaxQueuedPerClient: 1})
+
+		started, release := make(chan struct{}), make(chan struct{})
+		go func() {
+			_, _ = l.unaryInterceptor(context.Background(), nil, chunkInfo, func(context.Context, any) (any, error) {
+				close(started)
+				<-release
+				return &pbg.ChunkResponse{}, nil
+			})
+		}()
+		<-started
+
+		// второй чанк ждет в очереди клиента
+		queuedCtx, cancelQueued := context.WithCancel(context.Background())
+		defer cancelQueued()
+		go func() {
+			_, _ = l.unaryInterceptor(queuedCtx, nil, chunkInfo, ok)
+		}()
+		require.Eventually(t, func() bool {
+			l.scheduler.mu.Lock()
+			defer l.scheduler.mu.Unlock()
+			return len(l.scheduler.ring) == 1
+		}, time.Second, time.Millisecond)
+
+		_, err := l.unaryInterceptor(context.Background(), nil, chunkInfo, ok)
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+		assert.Equal(t, models.ReasonOverloaded, reasonOf(t, err))
+
+		// health-check не ограничивается
+		_, err = l.unaryInterceptor(context.Background(), nil,
+			&grpc.UnaryServerInfo{FullMethod: healthMethodPrefix + "Check"}, ok)
+		assert.NoError(t, err)
+
+		close(release)
+	})
+
+	t.Run("бюджет времени на чанк", func(t *testing.T) {
+		l := newLimits(&config.GRPCServerConfig{MaxChunkDuration: "10ms"})
+
+		_, err := l.unaryInterceptor(context.Background(), nil, chunkInfo, func(ctx context.Context, _ any) (any, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+		assert.Equal(t, models.ReasonOverloaded, reasonOf(t, err), "чанк можно повторить на другом сервере")
+	})
+
+	t.Run("дедлайн клиента не считается превышением лимита", func(t *testing.T) {
+		l := newLimits(&config.GRPCServerConfig{MaxChunkDuration: "1s"})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err := l.unaryInterceptor(ctx, nil, chunkInfo, func(ctx context.Context, _ any) (any, error) {
+			<-ctx.Done()
+			return nil, status.FromContextError(ctx.Err()).Err()
+		})
+		assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	})
+
+	t.Run("размер ответа", func(t *testing.T) {
+		l := newLimits(&config.GRPCServerConfig{MaxResponseSize: 16})
+
+		_, err := l.unaryInterceptor(context.Background(), nil, chunkInfo, func(context.Context, any) (any, error) {
+			return &pbg.ChunkResponse{Matches: []*pbg.Match{{Content: make([]byte, 32)}}}, nil
+		})
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+		assert.Equal(t, models.ReasonLimitExceeded, reasonOf(t, err))
+	})
+}
+
+func TestFairScheduler(t *testing.T) {
+	t.Run("слоты раздаются клиентам по кругу", func(t *testing.T) {
+		s := newFairScheduler(1, 10)
+		require.NoError(t, s.acquire(context.Background(), "heavy"))
+
+		order := make(chan string, 4)
+		queueLen := func(client string) int {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			return len(s.queues[client])
+		}
+		enqueue := func(client string) {
+			queued := queueLen(client)
+			go func() {
+				if err := s.acquire(context.Background(), client); err == nil {
+					order <- client
+				}
+			}()
+			require.Eventually(t, func() bool {
+				return queueLen(client) == queued+1
+			}, time.Second, time.Millisecond)
+		}
+
+		// тяжелый клиент поставил в очередь три чанка раньше легкого
+		enqueue("heavy")
+		enqueue("heavy")
+		enqueue("heavy")
+		enqueue("light")
+
+		var got []string
+		for range 4 {
+			s.release()
+			got = append(got, <-order)
+		}
+
+		assert.Equal(t, []string{"heavy", "light", "heavy", "heavy"}, got)
+	})
+
+	t.Run("переполнение очереди клиента", func(t *testing.T) {
+		s := newFairScheduler(1, 0)
+		require.NoError(t, s.acquire(context.Background(), "a"))
+
+		err := s.acquire(context.Background(), "a")
+		assert.ErrorIs(t, err, models.ErrOverloaded)
+	})
+
+	t.Run("отмена ожидания освобождает место в очереди", func(t *testing.T) {
+		s := newFairScheduler(1, 1)
+		require.NoError(t, s.acquire(context.Background(), "a"))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		err := s.acquire(ctx, "b")
+		assert.ErrorIs(t, err, models.ErrCancelled)
+		assert.Empty(t, s.ring)
+
+		s.release()
+		assert.Equal(t, 1, s.free)
+	})
+}
+
+func TestRateLimiter(t *testing.T) {
+	rl := newRateLimiter(&config.GRPCServerConfig{RateLimit: 2, RateBurst: 2})
+	require.NotNil(t, rl)
+
+	now := time.Unix(0, 0)
+	rl.now = func() time.Time { return now }
+
+	ok, _ := rl.allow("a")
+	assert.True(t, ok)
+	ok, _ = rl.allow("a")
+	assert.True(t, ok)
+
+	ok, wait := rl.allow("a")
+	assert.False(t, ok)
+	assert.Equal(t, 500*time.Millisecond, wait)
+
+	// другой клиент не затронут
+	ok, _ = rl.allow("b")
+	assert.True(t, ok)
+
+	now = now.Add(500 * time.Millisecond)
+	ok, _ = rl.allow("a")
+	assert.True(t, ok)
+
+	assert.Nil(t, newRateLimiter(&config.GRPCServerConfig{}))
+}
@@ -0,0 +1,171 @@
+package server
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"google.golang.org/genproto/googleapis/rpc/code"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"github.com/sunr3d/quorum-grep/models"
+	pbg "github.com/sunr3d/quorum-grep/proto/grepsvc"
+)
+
+const metricsNamespace = "quorum_grep"
+
+// metrics - метрики сервера в формате Prometheus.
+type metrics struct {
+	registry *prometheus.Registry
+
+	chunks   *prometheus.CounterVec
+	bytes    prometheus.Counter
+	lines    prometheus.Counter
+	matches  prometheus.Counter
+	latency  *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+	inFlight prometheus.Gauge
+
+	// totals - те же счетчики для AdminService.GetStats.
+	totals struct {
+		chunks, failed, bytes, lines, matches atomic.Int64
+	}
+}
+
+// newMetrics - конструктор metrics с отдельным реестром.
+func newMetrics() *metrics {
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		chunks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "chunks_total",
+			Help:      "Обработанные чанки по коду ответа.",
+		}, []string{"code"}),
+		bytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "scanned_bytes_total",
+			Help:      "Байты данных в успешно обработанных чанках.",
+		}),
+		lines: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "scanned_lines_total",
+			Help:      "Строки в успешно обработанных чанках.",
+		}),
+		matches: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "matches_total",
+			Help:      "Строки, возвращенные клиентам (совпадения и контекст).",
+		}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "request_duration_seconds",
+			Help:      "Время обработки запроса, включая ожидание в очереди.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+		}, []string{"method", "code"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "errors_total",
+			Help:      "Ошибки по причине (google.rpc.ErrorInfo) или коду gRPC.",
+		}, []string{"reason"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "requests_in_flight",
+			Help:      "Запросы в обработке, включая ожидающие слота.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		m.chunks, m.bytes, m.lines, m.matches, m.latency, m.errors, m.inFlight,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	return m
+}
+
+// registerPatternCache - метрики кэша скомпилированных шаблонов,
+// значения читаются из stats при каждом сборе.
+func (m *metrics) registerPatternCache(stats func() models.PatternCacheStats) {
+	gauge := func(name, help string, value func(models.PatternCacheStats) float64) prometheus.Collector {
+		return prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "pattern_cache",
+			Name:      name,
+			Help:      help,
+		}, func() float64 { return value(stats()) })
+	}
+	counter := func(name, help string, value func(models.PatternCacheStats) float64) prometheus.Collector {
+		return prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "pattern_cache",
+			Name:      name,
+			Help:      help,
+		}, func() float64 { return value(stats()) })
+	}
+
+	m.registry.MustRegister(
+		gauge("size", "Шаблоны в кэше.", func(s models.PatternCacheStats) float64 { return float64(s.Size) }),
+		gauge("capacity", "Размер кэша.", func(s models.PatternCacheStats) float64 { return float64(s.Capacity) }),
+		counter("hits_total", "Попадания в кэш.", func(s models.PatternCacheStats) float64 { return float64(s.Hits) }),
+		counter("misses_total", "Промахи кэша (компиляции шаблона).", func(s models.PatternCacheStats) float64 { return float64(s.Misses) }),
+		counter("evictions_total", "Вытеснения из кэша.", func(s models.PatternCacheStats) float64 { return float64(s.Evictions) }),
+	)
+}
+
+// unaryInterceptor - учет запросов; стоит первым в цепочке, чтобы видеть
+// и отказы аутентификации и лимитов.
+func (m *metrics) unaryInterceptor(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	if controlMethod(info.FullMethod) {
+		return handler(ctx, req)
+	}
+
+	m.inFlight.Inc()
+	defer m.inFlight.Dec()
+
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	grpcCode := status.Code(err).String()
+
+	m.latency.WithLabelValues(info.FullMethod, grpcCode).Observe(time.Since(start).Seconds())
+	if err != nil {
+		m.errors.WithLabelValues(errorReason(err)).Inc()
+	}
+
+	if chunk, ok := req.(*pbg.ChunkRequest); ok {
+		m.chunks.WithLabelValues(grpcCode).Inc()
+		m.totals.chunks.Add(1)
+		if res, ok := resp.(*pbg.ChunkResponse); ok && err == nil {
+			m.bytes.Add(float64(len(chunk.Data)))
+			m.lines.Add(float64(len(chunk.LineNumbers)))
+			m.matches.Add(float64(len(res.Matches)))
+			m.totals.bytes.Add(int64(len(chunk.Data)))
+			m.totals.lines.Add(int64(len(chunk.LineNumbers)))
+			m.totals.matches.Add(int64(len(res.Matches)))
+		} else {
+			m.totals.failed.Add(1)
+		}
+	}
+
+	return resp, err
+}
+
+// errorReason - причина ошибки из google.rpc.ErrorInfo, иначе код gRPC.
+func errorReason(err error) string {
+	st := status.Convert(err)
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.Domain == models.ErrorDomain {
+			return info.Reason
+		}
+	}
+
+	return code.Code(st.Code()).String()
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/sunr3d/quorum-grep/internal/grpcerr"
+	"github.com/sunr3d/quorum-grep/models"
+	pbg "github.com/sunr3d/quorum-grep/proto/grepsvc"
+)
+
+func TestMetrics_unaryInterceptor(t *testing.T) {
+	m := newMetrics()
+	info := &grpc.UnaryServerInfo{FullMethod: "/grepsvc.GrepService/ProcessChunk"}
+	req := &pbg.ChunkRequest{Data: []byte("a\nb\nc\n"), LineNumbers: []int64{1, 2, 3}}
+
+	_, err := m.unaryInterceptor(context.Background(), req, info, func(context.Context, any) (any, error) {
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.inFlight))
+		return &pbg.ChunkResponse{Matches: []*pbg.Match{{LineNumber: 2}}}, nil
+	})
+	require.NoError(t, err)
+
+	_, err = m.unaryInterceptor(context.Background(), req, info, func(ctx context.Context, _ any) (any, error) {
+		return nil, grpcerr.ToStatus(ctx, &models.RateLimitError{ClientID: "x"}, nil)
+	})
+	require.Error(t, err)
+
+	_, err = m.unaryInterceptor(context.Background(), req, info, func(context.Context, any) (any, error) {
+		return nil, status.Error(codes.Unavailable, "down")
+	})
+	require.Error(t, err)
+
+	assert.Equal(t, 0.0, testutil.ToFloat64(m.inFlight))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.chunks.WithLabelValues("OK")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.chunks.WithLabelValues("ResourceExhausted")))
+	assert.Equal(t, float64(len(req.Data)), testutil.ToFloat64(m.bytes))
+	assert.Equal(t, 3.0, testutil.ToFloat64(m.lines))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.matches))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.errors.WithLabelValues(models.ReasonRateLimited)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.errors.WithLabelValues("UNAVAILABLE")))
+	assert.Equal(t, 3, testutil.CollectAndCount(m.latency))
+}
+
+func TestMetrics_registerPatternCache(t *testing.T) {
+	m := newMetrics()
+	m.registerPatternCache(func() models.PatternCacheStats {
+		return models.PatternCacheStats{Size: 2, Capacity: 256, Hits: 10, Misses: 2}
+	})
+
+	expected := `
+# HELP quorum_grep_pattern_cache_hits_total Попадания в кэш.
+# TYPE quorum_grep_pattern_cache_hits_total counter
+quorum_grep_pattern_cache_hits_total 10
+# HELP quorum_grep_pattern_cache_size Шаблоны в кэше.
+# TYPE quorum_grep_pattern_cache_size gauge
+quorum_grep_pattern_cache_size 2
+`
+	err := testutil.GatherAndCompare(m.registry, strings.NewReader(expected),
+		"quorum_grep_pattern_cache_hits_total", "quorum_grep_pattern_cache_size")
+	assert.NoError(t, err)
+}
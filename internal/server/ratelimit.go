@@ -0,0 +1,123 @@
+package server
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/wb-go/wbf/zlog"
+
+	"github.com/sunr3d/quorum-grep/internal/config"
+	"github.com/sunr3d/quorum-grep/internal/grpcerr"
+	"github.com/sunr3d/quorum-grep/models"
+)
+
+// bucketIdleTTL - через сколько простоя бакет клиента удаляется.
+const bucketIdleTTL = 10 * time.Minute
+
+// rateLimiter - token bucket на каждого клиента.
+type rateLimiter struct {
+	rate  float64 // токенов в секунду
+	burst float64
+	now   func() time.Time
+
+	mu          sync.Mutex
+	buckets     map[string]*tokenBucket
+	lastCleanup time.Time
+}
+
+type tokenBucket struct {
+	tokens   float64
+	updated  time.Time
+	lastSeen time.Time
+}
+
+// newRateLimiter - конструктор rateLimiter, nil если лимит не задан.
+func newRateLimiter(cfg *config.GRPCServerConfig) *rateLimiter {
+	if cfg.RateLimit <= 0 {
+		return nil
+	}
+
+	burst := float64(cfg.RateBurst)
+	if burst < 1 {
+		burst = math.Max(1, math.Ceil(cfg.RateLimit))
+	}
+
+	return &rateLimiter{
+		rate:    cfg.RateLimit,
+		burst:   burst,
+		now:     time.Now,
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// allow - забирает токен клиента. Если токенов нет, возвращает
+// время до появления следующего.
+func (r *rateLimiter) allow(client string) (bool, time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	r.cleanup(now)
+
+	b, ok := r.buckets[client]
+	if !ok {
+		b = &tokenBucket{tokens: r.burst, updated: now}
+		r.buckets[client] = b
+	}
+	b.lastSeen = now
+
+	b.tokens = math.Min(r.burst, b.tokens+now.Sub(b.updated).Seconds()*r.rate)
+	b.updated = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	wait := time.Duration((1 - b.tokens) / r.rate * float64(time.Second))
+
+	return false, wait
+}
+
+// cleanup - удаление бакетов клиентов, которые давно не обращались.
+func (r *rateLimiter) cleanup(now time.Time) {
+	if now.Sub(r.lastCleanup) < time.Minute {
+		return
+	}
+	r.lastCleanup = now
+
+	for client, b := range r.buckets {
+		if now.Sub(b.lastSeen) > bucketIdleTTL {
+			delete(r.buckets, client)
+		}
+	}
+}
+
+// unaryInterceptor - отклоняет запросы клиента сверх лимита
+// с RESOURCE_EXHAUSTED и google.rpc.RetryInfo.
+func (r *rateLimiter) unaryInterceptor(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	if controlMethod(info.FullMethod) {
+		return handler(ctx, req)
+	}
+
+	client := clientID(ctx)
+	if ok, wait := r.allow(client); !ok {
+		zlog.Logger.Warn().
+			Str("client", client).
+			Str("client_id", headerID(ctx)).
+			Dur("retry_after", wait).
+			Msg("Запрос отклонен: превышен лимит запросов клиента")
+		return nil, grpcerr.ToStatus(ctx, &models.RateLimitError{ClientID: client, RetryAfter: wait}, nil)
+	}
+
+	return handler(ctx, req)
+}
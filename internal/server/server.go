@@ -2,15 +2,28 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
+	"net/http"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc/filters"
+
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 
 	"github.com/wb-go/wbf/zlog"
 
 	"github.com/sunr3d/quorum-grep/internal/config"
+	"github.com/sunr3d/quorum-grep/internal/tlsutil"
+	"github.com/sunr3d/quorum-grep/internal/tracing"
+	"github.com/sunr3d/quorum-grep/models"
+	pba "github.com/sunr3d/quorum-grep/proto/adminsvc"
 )
 
 const (
@@ -18,31 +31,176 @@ const (
 )
 
 type Server struct {
-	addr       string
-	grpcServer *grpc.Server
+	addr         string
+	metricsAddr  string
+	grpcServer   *grpc.Server
+	healthServer *health.Server
+	metrics      *metrics
+
+	// для AdminService
+	started    time.Time
+	limits     *limits
+	rate       *rateLimiter
+	drainer    *drainer
+	faults     *faultInjector
+	tls        bool
+	auth       bool
+	caps       models.Capabilities
+	cacheStats func() models.PatternCacheStats
 }
 
 // New - создает новый сервер gRPC.
-func New(cfg *config.GRPCServerConfig) *Server {
-	grpcServer := grpc.NewServer()
+// Регистрирует стандартный сервис grpc.health.v1.Health.
+// Лимиты ресурсов из cfg применяются ко всем сервисам, кроме health.
+// Если в cfg.TLS задан сертификат, сервер принимает только TLS соединения.
+// Если в cfg.Auth задан файл токенов или ключ JWT, запросы без токена отклоняются.
+// Регистрирует AdminService: сведения о сервере, статистика и вывод из работы,
+// а если включены cfg.Faults - переключение неисправностей.
+func New(cfg *config.GRPCServerConfig) (*Server, error) {
+	lim := newLimits(cfg)
+
+	tlsCfg, err := tlsutil.NewServerConfig(cfg.TLS)
+	if err != nil {
+		return nil, fmt.Errorf("tlsutil.NewServerConfig: %w", err)
+	}
+
+	authn, err := newAuth(&cfg.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("newAuth: %w", err)
+	}
+
+	faults, err := newFaultInjector(&cfg.Faults)
+	if err != nil {
+		return nil, fmt.Errorf("newFaultInjector: %w", err)
+	}
+
+	guard, err := newAdminGuard(cfg, authn != nil)
+	if err != nil {
+		return nil, fmt.Errorf("newAdminGuard: %w", err)
+	}
+
+	m := newMetrics()
+	d := &drainer{}
 
-	return &Server{
-		addr:       fmt.Sprintf(":%d", cfg.Port),
-		grpcServer: grpcServer,
+	interceptors := []grpc.UnaryServerInterceptor{m.unaryInterceptor}
+	var streamInterceptors []grpc.StreamServerInterceptor
+	if authn != nil {
+		interceptors = append(interceptors, authn.unaryInterceptor)
+		streamInterceptors = append(streamInterceptors, authn.streamInterceptor)
 	}
+	if guard != nil {
+		interceptors = append(interceptors, guard.unaryInterceptor)
+	}
+	// до лимитов: в in-flight попадают и чанки, ожидающие слота
+	interceptors = append(interceptors, d.unaryInterceptor)
+	rl := newRateLimiter(cfg)
+	if rl != nil {
+		interceptors = append(interceptors, rl.unaryInterceptor)
+	}
+	if faults != nil {
+		// неисправности до лимитов: запрос без ответа ждет дедлайна клиента,
+		// а не истечения бюджета сервера на чанк
+		interceptors = append(interceptors, faults.unaryInterceptor)
+	}
+	interceptors = append(interceptors, lim.unaryInterceptor)
+
+	opts := lim.serverOptions()
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(interceptors...),
+		grpc.ChainStreamInterceptor(streamInterceptors...),
+	)
+	if tlsCfg != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsCfg)))
+	}
+	if tracing.Enabled(cfg.Tracing) {
+		opts = append(opts, grpc.StatsHandler(otelgrpc.NewServerHandler(
+			otelgrpc.WithFilter(filters.Not(filters.HealthCheck())),
+		)))
+	}
+
+	grpcServer := grpc.NewServer(opts...)
+
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+
+	srv := &Server{
+		addr:         fmt.Sprintf(":%d", cfg.Port),
+		grpcServer:   grpcServer,
+		healthServer: healthServer,
+		metrics:      m,
+		started:      time.Now(),
+		limits:       lim,
+		rate:         rl,
+		drainer:      d,
+		faults:       faults,
+		tls:          tlsCfg != nil,
+		auth:         authn != nil,
+	}
+	// без явного доступа AdminService не регистрируется: Drain и SetFaults
+	// не должны быть доступны любому, кто видит порт
+	if guard != nil {
+		pba.RegisterAdminServiceServer(grpcServer, &adminHandler{srv: srv})
+	}
+	if cfg.MetricsPort > 0 {
+		srv.metricsAddr = fmt.Sprintf(":%d", cfg.MetricsPort)
+	}
+
+	return srv, nil
 }
 
-// Run - запускает сервер gRPC с graceful shutdown.
+// RegisterPatternCache - экспорт статистики кэша шаблонов в метрики.
+func (s *Server) RegisterPatternCache(stats func() models.PatternCacheStats) {
+	s.metrics.registerPatternCache(stats)
+	s.cacheStats = stats
+}
+
+// SetFaults - замена неисправностей, как AdminService.SetFaults; пустой
+// rules убирает все неисправности. Ошибка, если неисправности не включены
+// в cfg.Faults или правила некорректны.
+func (s *Server) SetFaults(rules []MethodFaults) error {
+	if s.faults == nil {
+		return errors.New("внесение неисправностей не включено")
+	}
+
+	return s.faults.set(true, rules)
+}
+
+// SetCapabilities - возможности сервиса поиска для AdminService.GetInfo.
+func (s *Server) SetCapabilities(caps models.Capabilities) {
+	s.caps = caps
+}
+
+// Run - запускает сервер gRPC на порту из конфига с graceful shutdown.
 func (s *Server) Run(ctx context.Context) error {
 	listener, err := net.Listen("tcp", s.addr)
 	if err != nil {
 		return fmt.Errorf("net.Listen %s: %w", s.addr, err)
 	}
 
+	return s.Serve(ctx, listener)
+}
+
+// Serve - обслуживает запросы на listener до отмены ctx, затем graceful shutdown.
+// Если задан порт метрик, рядом запускается HTTP сервер с /metrics.
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	if s.metricsAddr != "" {
+		stopMetrics, err := s.runMetrics()
+		if err != nil {
+			listener.Close()
+			return fmt.Errorf("runMetrics: %w", err)
+		}
+		defer stopMetrics()
+	}
+
+	// все зарегистрированные к этому моменту сервисы готовы принимать запросы
+	for name := range s.grpcServer.GetServiceInfo() {
+		s.healthServer.SetServingStatus(name, healthpb.HealthCheckResponse_SERVING)
+	}
+
 	srvErr := make(chan error, 1)
 	go func() {
 		zlog.Logger.Info().
-			Str("addr", s.addr).
+			Str("addr", listener.Addr().String()).
 			Msg("Запуск gRPC сервера...")
 		if err := s.grpcServer.Serve(listener); err != nil {
 			srvErr <- fmt.Errorf("grpcServer.Serve: %w", err)
@@ -54,6 +212,9 @@ func (s *Server) Run(ctx context.Context) error {
 		zlog.Logger.Info().
 			Msg("Получен сигнал о завершении работы, инициализация graceful shutdown...")
 
+		// клиенты перестают направлять новые чанки, пока идет drain
+		s.healthServer.Shutdown()
+
 		shutdownCtx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
 		defer cancel()
 
@@ -83,6 +244,38 @@ func (s *Server) Run(ctx context.Context) error {
 	}
 }
 
+// runMetrics - запуск HTTP сервера метрик, возвращает функцию остановки.
+func (s *Server) runMetrics() (func(), error) {
+	listener, err := net.Listen("tcp", s.metricsAddr)
+	if err != nil {
+		return nil, fmt.Errorf("net.Listen %s: %w", s.metricsAddr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(s.metrics.registry, promhttp.HandlerOpts{}))
+	httpServer := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		zlog.Logger.Info().
+			Str("addr", s.metricsAddr).
+			Msg("Запуск HTTP сервера метрик...")
+		if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			zlog.Logger.Error().
+				Err(err).
+				Msg("HTTP сервер метрик остановлен с ошибкой")
+		}
+	}()
+
+	return func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = httpServer.Shutdown(shutdownCtx)
+	}, nil
+}
+
 func (s *Server) GetGRPCServer() *grpc.Server {
 	return s.grpcServer
 }
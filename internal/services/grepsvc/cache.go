@@ -0,0 +1,102 @@
+package grepsvc
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/sunr3d/quorum-grep/models"
+)
+
+// DefaultPatternCacheSize - размер кэша скомпилированных шаблонов по умолчанию.
+const DefaultPatternCacheSize = 256
+
+// patternKey - ключ кэша: шаблон и флаги, влияющие на компиляцию.
+type patternKey struct {
+	pattern    string
+	fixed      bool
+	ignoreCase bool
+}
+
+type cacheEntry struct {
+	key     patternKey
+	matcher *matcher
+}
+
+// patternCache - потокобезопасный LRU-кэш скомпилированных шаблонов.
+type patternCache struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[patternKey]*list.Element
+	order    *list.List
+
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+// newPatternCache - конструктор patternCache.
+func newPatternCache(capacity int) *patternCache {
+	if capacity <= 0 {
+		capacity = DefaultPatternCacheSize
+	}
+
+	return &patternCache{
+		capacity: capacity,
+		items:    make(map[patternKey]*list.Element, capacity),
+		order:    list.New(),
+	}
+}
+
+// get - возвращает шаблон из кэша, либо компилирует и кладет его в кэш.
+// Компиляция выполняется вне блокировки, чтобы не задерживать другие запросы.
+func (c *patternCache) get(key patternKey, compile func() (*matcher, error)) (*matcher, error) {
+	c.mu.Lock()
+	if el, ok := c.items[key]; ok {
+		c.order.MoveToFront(el)
+		c.hits++
+		m := el.Value.(*cacheEntry).matcher
+		c.mu.Unlock()
+		return m, nil
+	}
+	c.misses++
+	c.mu.Unlock()
+
+	m, err := compile()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// пока компилировали, шаблон мог положить другой запрос
+	if el, ok := c.items[key]; ok {
+		c.order.MoveToFront(el)
+		return el.Value.(*cacheEntry).matcher, nil
+	}
+
+	c.items[key] = c.order.PushFront(&cacheEntry{key: key, matcher: m})
+
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*cacheEntry).key)
+		c.evictions++
+	}
+
+	return m, nil
+}
+
+// stats - возвращает снимок статистики кэша.
+func (c *patternCache) stats() models.PatternCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return models.PatternCacheStats{
+		Size:      c.order.Len(),
+		Capacity:  c.capacity,
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+	}
+}
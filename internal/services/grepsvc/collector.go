@@ -0,0 +1,70 @@
+package grepsvc
+
+import (
+	"bytes"
+
+	"github.com/sunr3d/quorum-grep/models"
+)
+
+// collector - сборщик совпадений и строк контекста из буфера чанка.
+// Строки добавляются строго по возрастанию, поэтому вместо множества
+// уже добавленных строк хранится только граница next.
+type collector struct {
+	data     []byte
+	task     *models.Task
+	linesLen int
+	matches  []models.Match
+
+	next    int // индекс первой еще не добавленной строки
+	nextPos int // позиция строки next в data
+}
+
+// add - добавление совпавшей строки pivot, начинающейся с позиции pivotPos,
+// вместе с контекстом [start, end].
+func (c *collector) add(pivot, pivotPos, start, end int) {
+	if start > c.next {
+		c.seek(start, pivot, pivotPos)
+	}
+
+	for c.next <= end && c.next < c.linesLen {
+		lineEnd := lineEnd(c.data, c.nextPos)
+		c.matches = append(c.matches, models.Match{
+			Content:    c.data[c.nextPos:lineEnd],
+			LineNumber: c.task.LineNumbers[c.next],
+		})
+		c.next++
+		c.nextPos = lineEnd + 1
+	}
+}
+
+// seek - перенос границы next на строку target <= pivot.
+// Позиция target ищется назад от начала строки pivot.
+func (c *collector) seek(target, pivot, pivotPos int) {
+	pos := pivotPos
+	for i := pivot; i > target; i-- {
+		pos = bytes.LastIndexByte(c.data[:pos-1], '\n') + 1
+	}
+
+	c.next = target
+	c.nextPos = pos
+}
+
+// countLines - количество строк в буфере; завершающий '\n' не дает пустой строки.
+func countLines(data []byte) int {
+	n := bytes.Count(data, []byte("\n"))
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		n++
+	}
+
+	return n
+}
+
+// lineEnd - позиция конца строки, начинающейся с pos: '\n' или len(data).
+func lineEnd(data []byte, pos int) int {
+	idx := bytes.IndexByte(data[pos:], '\n')
+	if idx < 0 {
+		return len(data)
+	}
+
+	return pos + idx
+}
@@ -0,0 +1,36 @@
+package grepsvc
+
+import "github.com/sunr3d/quorum-grep/models"
+
+// LineMatcher - проверка отдельных строк той же реализацией, что
+// используется сервером. Нужен клиенту, чтобы отличить совпавшие строки
+// от строк контекста и найти границы совпадений для вывода.
+type LineMatcher struct {
+	m      *matcher
+	invert bool
+}
+
+// NewLineMatcher - конструктор LineMatcher; ошибка - *models.PatternError.
+func NewLineMatcher(opts models.GrepOptions) (*LineMatcher, error) {
+	m, err := (&grepService{}).compileMatcher(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LineMatcher{m: m, invert: opts.Invert}, nil
+}
+
+// Selected - выбрана ли строка поиском с учетом -v.
+func (l *LineMatcher) Selected(line []byte) bool {
+	return l.m.match(line) != l.invert
+}
+
+// Submatches - границы [start, end) совпадений шаблона в строке.
+// Для -v совпадений в выбранных строках нет, возвращается nil.
+func (l *LineMatcher) Submatches(line []byte) [][]int {
+	if l.invert {
+		return nil
+	}
+
+	return l.m.submatches(line)
+}
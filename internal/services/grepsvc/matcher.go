@@ -0,0 +1,325 @@
+package grepsvc
+
+import (
+	"bytes"
+	"regexp"
+	"regexp/syntax"
+	"unicode/utf8"
+
+	"github.com/sunr3d/quorum-grep/models"
+)
+
+// matcher - скомпилированный шаблон с быстрыми путями поиска.
+//
+// Если в шаблоне есть обязательная подстрока (literal), кандидаты ищутся
+// по всему буферу чанка через bytes.Index, а регулярное выражение
+// запускается только на строках-кандидатах. Если шаблон целиком является
+// подстрокой (-F или регулярка без метасимволов), регулярное выражение
+// не используется вовсе (re == nil).
+type matcher struct {
+	re      *regexp.Regexp
+	literal *literal
+}
+
+// literal - подстрока для предварительной фильтрации.
+// При fold == true поиск ведется без учета регистра ASCII, а bytes
+// хранится в нижнем регистре.
+type literal struct {
+	bytes []byte
+	fold  bool
+}
+
+// newMatcher - создание matcher из опций поиска.
+func newMatcher(opts models.GrepOptions, compile func() (*regexp.Regexp, error)) (*matcher, error) {
+	// шаблон с некорректным UTF-8 отклоняет regexp, как и без быстрого пути
+	if opts.Fixed && utf8.ValidString(opts.Pattern) {
+		if lit := fixedLiteral([]byte(opts.Pattern), opts.IgnoreCase); lit != nil {
+			return &matcher{literal: lit}, nil
+		}
+	}
+
+	re, err := compile()
+	if err != nil {
+		return nil, err
+	}
+
+	// ошибка здесь невозможна: то же выражение только что скомпилировал regexp
+	tree, err := syntax.Parse(re.String(), syntax.Perl)
+	if err != nil {
+		return &matcher{re: re}, nil
+	}
+	tree = tree.Simplify()
+
+	if tree.Op == syntax.OpLiteral {
+		if lit := fixedLiteral(runesToBytes(tree.Rune), tree.Flags&syntax.FoldCase != 0); lit != nil {
+			return &matcher{literal: lit}, nil
+		}
+	}
+
+	return &matcher{
+		re:      re,
+		literal: requiredLiteral(tree),
+	}, nil
+}
+
+// match - проверка совпадения одной строки.
+func (m *matcher) match(line []byte) bool {
+	if m.literal != nil && m.literal.index(line) < 0 {
+		return false
+	}
+
+	return m.re == nil || m.re.Match(line)
+}
+
+// next - поиск первой совпадающей строки в data, начиная с позиции from.
+// from должен указывать на начало строки. Возвращает границы строки
+// [start, end), где end - позиция '\n' или len(data).
+func (m *matcher) next(data []byte, from int) (int, int, bool) {
+	for from < len(data) {
+		start := from
+		if m.literal != nil {
+			idx := m.literal.index(data[from:])
+			if idx < 0 {
+				return 0, 0, false
+			}
+			start = bytes.LastIndexByte(data[:from+idx], '\n') + 1
+			from += idx
+		}
+
+		end := bytes.IndexByte(data[from:], '\n')
+		if end < 0 {
+			end = len(data)
+		} else {
+			end += from
+		}
+
+		if m.re == nil || m.re.Match(data[start:end]) {
+			return start, end, true
+		}
+
+		from = end + 1
+	}
+
+	return 0, 0, false
+}
+
+// index - позиция первого вхождения подстроки в s или -1.
+func (l *literal) index(s []byte) int {
+	if l.fold {
+		return indexFoldASCII(s, l.bytes)
+	}
+
+	return bytes.Index(s, l.bytes)
+}
+
+// fixedLiteral - подстрока для поиска фиксированной строки.
+// Возвращает nil, если быстрый путь неприменим и нужен regexp.
+func fixedLiteral(pattern []byte, ignoreCase bool) *literal {
+	// строка не может содержать '\n', такой шаблон обрабатывает regexp
+	if bytes.IndexByte(pattern, '\n') >= 0 {
+		return nil
+	}
+
+	if !ignoreCase {
+		return &literal{bytes: pattern}
+	}
+
+	if !foldableASCII(pattern) {
+		return nil
+	}
+
+	return &literal{bytes: bytes.ToLower(pattern), fold: true}
+}
+
+// requiredLiteral - самая длинная подстрока, которая обязана входить
+// в любое совпадение с выражением. Возвращает nil, если такой нет.
+func requiredLiteral(re *syntax.Regexp) *literal {
+	var best *literal
+
+	consider := func(lit *literal) {
+		if lit != nil && (best == nil || len(lit.bytes) > len(best.bytes)) {
+			best = lit
+		}
+	}
+
+	switch re.Op {
+	case syntax.OpLiteral:
+		consider(fixedLiteral(runesToBytes(re.Rune), re.Flags&syntax.FoldCase != 0))
+
+	case syntax.OpCapture, syntax.OpPlus:
+		consider(requiredLiteral(re.Sub[0]))
+
+	case syntax.OpRepeat:
+		if re.Min >= 1 {
+			consider(requiredLiteral(re.Sub[0]))
+		}
+
+	case syntax.OpConcat:
+		// соседние литералы с одинаковым регистром склеиваются в один
+		var run []rune
+		var runFold bool
+		flush := func() {
+			if len(run) > 0 {
+				consider(fixedLiteral(runesToBytes(run), runFold))
+				run = nil
+			}
+		}
+
+		for _, sub := range re.Sub {
+			if sub.Op == syntax.OpLiteral {
+				fold := sub.Flags&syntax.FoldCase != 0
+				if len(run) > 0 && fold != runFold {
+					flush()
+				}
+				run = append(run, sub.Rune...)
+				runFold = fold
+				continue
+			}
+
+			flush()
+			consider(requiredLiteral(sub))
+		}
+		flush()
+
+	default:
+	}
+
+	if best != nil && len(best.bytes) == 0 {
+		return nil
+	}
+
+	return best
+}
+
+// foldableASCII - можно ли искать строку без учета регистра побайтово.
+// Буквы k и s исключены: в Unicode они совпадают с не-ASCII символами
+// (K - знак кельвина, ſ - длинная s), и побайтовый поиск их бы пропустил.
+func foldableASCII(s []byte) bool {
+	for _, b := range s {
+		if b >= utf8.RuneSelf {
+			return false
+		}
+		switch b {
+		case 'k', 'K', 's', 'S':
+			return false
+		}
+	}
+
+	return true
+}
+
+// indexFoldASCII - bytes.Index без учета регистра ASCII.
+// sep должен быть в нижнем регистре.
+func indexFoldASCII(s, sep []byte) int {
+	n := len(sep)
+	if n == 0 {
+		return 0
+	}
+
+	lower, upper := sep[0], toUpperASCII(sep[0])
+	if lower == upper {
+		return indexFoldFrom(s, sep, func(from int) int {
+			return indexByteFrom(s, from, lower)
+		})
+	}
+
+	// позиции следующих вхождений первого символа в обоих регистрах,
+	// пересчитываются только когда поиск ушел дальше них
+	nextLower, nextUpper := -1, -1
+
+	return indexFoldFrom(s, sep, func(from int) int {
+		if nextLower < from {
+			nextLower = indexByteFrom(s, from, lower)
+		}
+		if nextUpper < from {
+			nextUpper = indexByteFrom(s, from, upper)
+		}
+
+		return min(nextLower, nextUpper)
+	})
+}
+
+// indexFoldFrom - перебор кандидатов, найденных по первому символу.
+func indexFoldFrom(s, sep []byte, nextFirst func(from int) int) int {
+	n := len(sep)
+	for i := 0; i <= len(s)-n; {
+		p := nextFirst(i)
+		if p > len(s)-n {
+			return -1
+		}
+		if equalFoldASCII(s[p+1:p+n], sep[1:]) {
+			return p
+		}
+		i = p + 1
+	}
+
+	return -1
+}
+
+// indexByteFrom - позиция c в s начиная с from, либо len(s).
+func indexByteFrom(s []byte, from int, c byte) int {
+	idx := bytes.IndexByte(s[from:], c)
+	if idx < 0 {
+		return len(s)
+	}
+
+	return from + idx
+}
+
+// equalFoldASCII - сравнение s с lower без учета регистра ASCII.
+func equalFoldASCII(s, lower []byte) bool {
+	for i, b := range s {
+		if 'A' <= b && b <= 'Z' {
+			b += 'a' - 'A'
+		}
+		if b != lower[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func toUpperASCII(b byte) byte {
+	if 'a' <= b && b <= 'z' {
+		return b - ('a' - 'A')
+	}
+
+	return b
+}
+
+func runesToBytes(runes []rune) []byte {
+	buf := make([]byte, 0, len(runes))
+	for _, r := range runes {
+		buf = utf8.AppendRune(buf, r)
+	}
+
+	return buf
+}
+
+// submatches - границы всех непересекающихся совпадений в строке.
+func (m *matcher) submatches(line []byte) [][]int {
+	if m.re != nil {
+		if m.literal != nil && m.literal.index(line) < 0 {
+			return nil
+		}
+		return m.re.FindAllIndex(line, -1)
+	}
+
+	n := len(m.literal.bytes)
+	if n == 0 {
+		return [][]int{{0, 0}}
+	}
+
+	var out [][]int
+	for pos := 0; pos <= len(line)-n; {
+		idx := m.literal.index(line[pos:])
+		if idx < 0 {
+			break
+		}
+		out = append(out, []int{pos + idx, pos + idx + n})
+		pos += idx + n
+	}
+
+	return out
+}
@@ -0,0 +1,221 @@
+package grepsvc
+
+import (
+	"bytes"
+	"regexp"
+	"regexp/syntax"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sunr3d/quorum-grep/models"
+)
+
+func TestRequiredLiteral(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  string
+		expected string
+		fold     bool
+	}{
+		{name: "литерал", pattern: "error", expected: "error"},
+		{name: "самый длинный в конкатенации", pattern: `id=\d+ request failed`, expected: " request failed"},
+		{name: "группа", pattern: `(timeout)\s`, expected: "timeout"},
+		{name: "плюс", pattern: `(abc)+x`, expected: "abc"},
+		{name: "повтор с минимумом", pattern: `(abcd){2,3}`, expected: "abcd"},
+		{name: "без регистра", pattern: "(?i)error", expected: "error", fold: true},
+		{name: "альтернатива без обязательного литерала", pattern: "foo|bar", expected: ""},
+		{name: "необязательная часть", pattern: "(longer)?ab", expected: "ab"},
+		{name: "символы без регистра с k/s", pattern: "(?i)disk", expected: ""},
+		{name: "не-ASCII без регистра", pattern: "(?i)ошибка", expected: ""},
+		{name: "только классы символов", pattern: `\d+`, expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree, err := syntax.Parse(tt.pattern, syntax.Perl)
+			require.NoError(t, err)
+
+			lit := requiredLiteral(tree.Simplify())
+			if tt.expected == "" {
+				assert.Nil(t, lit)
+				return
+			}
+
+			require.NotNil(t, lit)
+			assert.Equal(t, tt.expected, string(lit.bytes))
+			assert.Equal(t, tt.fold, lit.fold)
+		})
+	}
+}
+
+func TestIndexFoldASCII(t *testing.T) {
+	tests := []struct {
+		s, sep   string
+		expected int
+	}{
+		{s: "hello world", sep: "world", expected: 6},
+		{s: "Hello WORLD", sep: "world", expected: 6},
+		{s: "wOrLd", sep: "world", expected: 0},
+		{s: "worl", sep: "world", expected: -1},
+		{s: "ww worldw", sep: "world", expected: 3},
+		{s: "Www World", sep: "world", expected: 4},
+		{s: "a-b-c", sep: "-c", expected: 3},
+		{s: "abc", sep: "", expected: 0},
+		{s: "", sep: "a", expected: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s+"/"+tt.sep, func(t *testing.T) {
+			assert.Equal(t, tt.expected, indexFoldASCII([]byte(tt.s), []byte(tt.sep)))
+		})
+	}
+}
+
+// TestMatcher_EquivalentToRegexp - быстрые пути должны давать
+// тот же результат, что и построчная проверка regexp.
+func TestMatcher_EquivalentToRegexp(t *testing.T) {
+	data := []byte("ERROR disk full\n" +
+		"info: all good\n" +
+		"\n" +
+		"Error: request 42 failed\n" +
+		"warn: retry request 43\n" +
+		"error\n" +
+		"some.pattern here\n" +
+		"somexpattern here\n" +
+		"ошибка ОШИБКА\n" +
+		"Kelvin K and long ſ\n" +
+		"tail without newline error")
+
+	tests := []models.GrepOptions{
+		{Pattern: "error"},
+		{Pattern: "error", IgnoreCase: true},
+		{Pattern: "ERROR", Fixed: true},
+		{Pattern: "error", Fixed: true, IgnoreCase: true},
+		{Pattern: "some.pattern", Fixed: true},
+		{Pattern: "some.pattern"},
+		{Pattern: `request \d+ (failed|timeout)`},
+		{Pattern: `(?:warn|info): \w+`},
+		{Pattern: "^$"},
+		{Pattern: "ошибка", IgnoreCase: true},
+		{Pattern: "ошибка", Fixed: true, IgnoreCase: true},
+		{Pattern: "kelvin k", IgnoreCase: true},
+		{Pattern: "long s", Fixed: true, IgnoreCase: true},
+		{Pattern: "", Fixed: true},
+		{Pattern: "a\nb", Fixed: true},
+	}
+
+	svc := &grepService{}
+	lines := bytes.Split(data, []byte("\n"))
+
+	for _, opts := range tests {
+		t.Run(opts.Pattern, func(t *testing.T) {
+			re, err := svc.makePattern(opts)
+			require.NoError(t, err)
+
+			m, err := newMatcher(opts, func() (*regexp.Regexp, error) {
+				return svc.makePattern(opts)
+			})
+			require.NoError(t, err)
+
+			var expected []int
+			for i, line := range lines {
+				assert.Equal(t, re.Match(line), m.match(line), "строка %d: %q", i, line)
+				if re.Match(line) {
+					expected = append(expected, i)
+				}
+			}
+
+			var got []int
+			pos, lineIdx := 0, 0
+			for {
+				start, end, ok := m.next(data, pos)
+				if !ok {
+					break
+				}
+				lineIdx += bytes.Count(data[pos:start], []byte("\n"))
+				got = append(got, lineIdx)
+				pos, lineIdx = end+1, lineIdx+1
+			}
+
+			assert.Equal(t, expected, got)
+		})
+	}
+}
+
+func BenchmarkMatcher(b *testing.B) {
+	task := benchChunk(4096)
+	svc := &grepService{}
+
+	cases := []struct {
+		name string
+		opts models.GrepOptions
+	}{
+		{name: "regexp_literal_prefilter", opts: models.GrepOptions{Pattern: `ERROR request \d+ (failed|timeout)`}},
+		{name: "fixed", opts: models.GrepOptions{Pattern: "ERROR request", Fixed: true}},
+		{name: "fixed_ignore_case", opts: models.GrepOptions{Pattern: "error", Fixed: true, IgnoreCase: true}},
+	}
+
+	for _, tc := range cases {
+		re, err := svc.makePattern(tc.opts)
+		require.NoError(b, err)
+
+		m, err := newMatcher(tc.opts, func() (*regexp.Regexp, error) { return re, nil })
+		require.NoError(b, err)
+
+		lines := bytes.Split(task.Data, []byte("\n"))
+
+		b.Run(tc.name+"/regexp_per_line", func(b *testing.B) {
+			b.SetBytes(int64(len(task.Data)))
+			for b.Loop() {
+				for _, line := range lines {
+					re.Match(line)
+				}
+			}
+		})
+
+		b.Run(tc.name+"/matcher", func(b *testing.B) {
+			b.SetBytes(int64(len(task.Data)))
+			for b.Loop() {
+				for pos := 0; ; {
+					_, end, ok := m.next(task.Data, pos)
+					if !ok {
+						break
+					}
+					pos = end + 1
+				}
+			}
+		})
+	}
+}
+
+func TestLineMatcher_Submatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     models.GrepOptions
+		line     string
+		selected bool
+		expected [][]int
+	}{
+		{name: "регулярка", opts: models.GrepOptions{Pattern: `id=\d+`}, line: "id=1 id=22", selected: true, expected: [][]int{{0, 4}, {5, 10}}},
+		{name: "литерал", opts: models.GrepOptions{Pattern: "ab"}, line: "abab-ab", selected: true, expected: [][]int{{0, 2}, {2, 4}, {5, 7}}},
+		{name: "без регистра", opts: models.GrepOptions{Pattern: "err", Fixed: true, IgnoreCase: true}, line: "ERR or Err", selected: true, expected: [][]int{{0, 3}, {7, 10}}},
+		{name: "префильтр без совпадения", opts: models.GrepOptions{Pattern: `error \d`}, line: "error x", selected: false},
+		{name: "инверсия", opts: models.GrepOptions{Pattern: "x", Invert: true}, line: "abc", selected: true},
+		{name: "пустой шаблон", opts: models.GrepOptions{Pattern: "", Fixed: true}, line: "abc", selected: true, expected: [][]int{{0, 0}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lm, err := NewLineMatcher(tt.opts)
+			require.NoError(t, err)
+
+			line := []byte(tt.line)
+			assert.Equal(t, tt.selected, lm.Selected(line))
+			if tt.selected {
+				assert.Equal(t, tt.expected, lm.Submatches(line))
+			}
+		})
+	}
+}
@@ -3,8 +3,17 @@ package grepsvc
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/sunr3d/quorum-grep/internal/interfaces/services"
 	"github.com/sunr3d/quorum-grep/models"
@@ -12,27 +21,60 @@ import (
 
 var _ services.GrepService = (*grepService)(nil)
 
-type grepService struct{}
+var tracer = otel.Tracer("github.com/sunr3d/quorum-grep/internal/services/grepsvc")
+
+// ignoreCasePrefix - флаг regexp для -i.
+const ignoreCasePrefix = "(?i)"
 
-// New - конструктор grepService.
+// scanWindow - сколько байт чанка просматривается между проверками отмены.
+const scanWindow = 1 << 20
+
+type grepService struct {
+	cache *patternCache
+}
+
+// New - конструктор grepService с кэшем шаблонов размера по умолчанию.
 func New() services.GrepService {
-	return &grepService{}
+	return NewWithCacheSize(DefaultPatternCacheSize)
+}
+
+// NewWithCacheSize - конструктор grepService с заданным размером кэша шаблонов.
+func NewWithCacheSize(cacheSize int) services.GrepService {
+	return &grepService{
+		cache: newPatternCache(cacheSize),
+	}
 }
 
 // ProcessChunk - метод для обработки кусочка данных.
-func (s *grepService) ProcessChunk(_ context.Context, task *models.Task) (*models.Result, error) {
-	lines := bytes.Split(task.Data, []byte("\n"))
-	lineLen := len(lines)
-	if lineLen > 0 && len(lines[lineLen-1]) == 0 {
-		lines = lines[:lineLen-1]
+// Буфер чанка не разбивается на строки: границы строк ищутся
+// только вокруг совпадений и строк контекста.
+func (s *grepService) ProcessChunk(ctx context.Context, task *models.Task) (*models.Result, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", models.ErrCancelled, err)
 	}
 
-	pattern, err := s.makePattern(task.Options)
+	m, err := s.getMatcher(ctx, task.Options)
 	if err != nil {
-		return nil, fmt.Errorf("makePattern: %w", err)
+		return nil, fmt.Errorf("getMatcher: %w", err)
 	}
 
-	matches := s.findMatches(lines, pattern, task)
+	ctx, span := startSpan(ctx, "grepsvc.scan")
+	matches, err := s.findMatches(ctx, m, task)
+	if span.IsRecording() {
+		span.SetAttributes(
+			attribute.Int("chunk.index", task.Index),
+			attribute.Int("chunk.bytes", len(task.Data)),
+			attribute.Int("chunk.matches", len(matches)),
+		)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+	span.End()
+	if err != nil {
+		return nil, fmt.Errorf("findMatches: %w", err)
+	}
 
 	return &models.Result{
 		Matches:    matches,
@@ -40,81 +82,142 @@ func (s *grepService) ProcessChunk(_ context.Context, task *models.Task) (*model
 	}, nil
 }
 
+// ValidatePattern - проверка шаблона той же реализацией, что используется при поиске.
+// Позволяет клиенту отклонить некорректный шаблон до отправки на серверы.
+func ValidatePattern(opts models.GrepOptions) error {
+	_, err := (&grepService{}).compileMatcher(opts)
+	return err
+}
+
+// PatternCacheStats - возвращает статистику кэша скомпилированных шаблонов.
+func (s *grepService) PatternCacheStats() models.PatternCacheStats {
+	return s.cache.stats()
+}
+
+// Capabilities - способы поиска и поддерживаемые опции: regexp - RE2
+// из стандартной библиотеки, literal - поиск подстроки без регулярного
+// выражения для -F и шаблонов без метасимволов.
+func (s *grepService) Capabilities() models.Capabilities {
+	return models.Capabilities{
+		Engines: []string{"regexp", "literal"},
+		Options: []string{"after", "before", "around", "count", "ignore_case", "invert", "fixed", "line_num"},
+	}
+}
+
 // Хелперы
 
-// findMatches - поиск совпадений в строках.
-func (s *grepService) findMatches(lines [][]byte, pattern *regexp.Regexp, task *models.Task) []models.Match {
-	matches := make([]models.Match, 0, len(lines)*2)
-	collected := make(map[int]struct{}, len(lines))
+// getMatcher - получение скомпилированного шаблона из кэша.
+// Компиляция при промахе кэша попадает в трассировку отдельным спаном.
+func (s *grepService) getMatcher(ctx context.Context, opts models.GrepOptions) (*matcher, error) {
+	key := patternKey{
+		pattern:    opts.Pattern,
+		fixed:      opts.Fixed,
+		ignoreCase: opts.IgnoreCase,
+	}
 
-	for i, line := range lines {
-		if s.matchLine(pattern, line, task.Options) {
-			contextBefore, contextAfter := s.getContext(lines, i, task, collected)
+	return s.cache.get(key, func() (*matcher, error) {
+		_, span := startSpan(ctx, "grepsvc.compilePattern")
+		defer span.End()
 
-			for _, ctxLine := range contextBefore {
-				matches = append(matches, models.Match{
-					Content:    ctxLine.Content,
-					LineNumber: ctxLine.LineNumber,
-				})
-			}
+		m, err := s.compileMatcher(opts)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		return m, err
+	})
+}
+
+// compileMatcher - компиляция matcher; ошибка компиляции возвращается как *models.PatternError.
+func (s *grepService) compileMatcher(opts models.GrepOptions) (*matcher, error) {
+	expr := patternExpr(opts)
+	m, err := newMatcher(opts, func() (*regexp.Regexp, error) {
+		return regexp.Compile(expr)
+	})
+	if err != nil {
+		return nil, newPatternError(opts, err)
+	}
 
-			if _, ok := collected[i]; !ok {
-				collected[i] = struct{}{}
-				matches = append(matches, models.Match{
-					Content:    line,
-					LineNumber: task.LineNumbers[i],
-				})
+	return m, nil
+}
+
+// findMatches - поиск совпадений в буфере чанка.
+// Без -v совпадающие строки ищутся по всему буферу через matcher.next,
+// с -v строки перебираются по очереди без выделения памяти.
+// Буфер просматривается окнами по scanWindow байт, между окнами
+// проверяется отмена ctx.
+func (s *grepService) findMatches(ctx context.Context, m *matcher, task *models.Task) ([]models.Match, error) {
+	data := task.Data
+	c := &collector{
+		data:     data,
+		task:     task,
+		linesLen: min(countLines(data), len(task.LineNumbers)),
+	}
+
+	if task.Options.Invert {
+		checkpoint := scanWindow
+		for pos, i := 0, 0; i < c.linesLen; i++ {
+			if pos >= checkpoint {
+				if err := ctx.Err(); err != nil {
+					return nil, fmt.Errorf("%w: %w", models.ErrCancelled, err)
+				}
+				checkpoint = pos + scanWindow
 			}
 
-			for _, ctxLine := range contextAfter {
-				matches = append(matches, models.Match{
-					Content:    ctxLine.Content,
-					LineNumber: ctxLine.LineNumber,
-				})
+			end := lineEnd(data, pos)
+			if s.matchLine(m, data[pos:end], task.Options) {
+				ctxStart, ctxEnd := s.getContextRange(i, c.linesLen, task.Options)
+				c.add(i, pos, ctxStart, ctxEnd)
 			}
+			pos = end + 1
 		}
+
+		return c.matches, nil
 	}
 
-	return matches
-}
+	pos, lineIdx := 0, 0
+	for pos < len(data) && lineIdx < c.linesLen {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("%w: %w", models.ErrCancelled, err)
+		}
 
-// getContext - получение контекста для совпадения.
-func (s *grepService) getContext(
-	lines [][]byte,
-	pivot int,
-	task *models.Task,
-	collected map[int]struct{},
-) ([]models.Match, []models.Match) {
-	start, end := s.getContextRange(pivot, len(lines), task.Options)
+		// окно заканчивается на границе строки, включая ее '\n'
+		window := data[:min(lineEnd(data, min(pos+scanWindow, len(data)))+1, len(data))]
 
-	contextBefore := make([]models.Match, 0, end-start+1)
-	contextAfter := make([]models.Match, 0, end-start+1)
+		for {
+			start, end, ok := m.next(window, pos)
+			if !ok {
+				break
+			}
+
+			lineIdx += bytes.Count(data[pos:start], []byte("\n"))
+			if lineIdx >= c.linesLen {
+				break
+			}
 
-	for i := start; i < pivot; i++ {
-		if _, ok := collected[i]; !ok {
-			collected[i] = struct{}{}
-			contextBefore = append(contextBefore, models.Match{
-				Content:    lines[i],
-				LineNumber: task.LineNumbers[i],
-			})
+			ctxStart, ctxEnd := s.getContextRange(lineIdx, c.linesLen, task.Options)
+			c.add(lineIdx, start, ctxStart, ctxEnd)
+
+			pos = end + 1
+			lineIdx++
 		}
-	}
 
-	for i := pivot + 1; i <= end; i++ {
-		if _, ok := collected[i]; !ok {
-			collected[i] = struct{}{}
-			contextAfter = append(contextAfter, models.Match{
-				Content:    lines[i],
-				LineNumber: task.LineNumbers[i],
-			})
+		if pos < len(window) {
+			lineIdx += bytes.Count(data[pos:len(window)], []byte("\n"))
+			pos = len(window)
 		}
 	}
 
-	return contextBefore, contextAfter
+	return c.matches, nil
 }
 
 // makePattern - создание регулярного выражения для поиска из паттерна и опций.
 func (s *grepService) makePattern(opts models.GrepOptions) (*regexp.Regexp, error) {
+	return regexp.Compile(patternExpr(opts))
+}
+
+// patternExpr - выражение для regexp.Compile.
+func patternExpr(opts models.GrepOptions) string {
 	pattern := opts.Pattern
 
 	if opts.Fixed {
@@ -122,16 +225,15 @@ func (s *grepService) makePattern(opts models.GrepOptions) (*regexp.Regexp, erro
 	}
 
 	if opts.IgnoreCase {
-		pattern = "(?i)" + pattern
+		return ignoreCasePrefix + pattern
 	}
 
-	return regexp.Compile(pattern)
+	return pattern
 }
 
-// matchLine - проверка совпадения строки с регулярным выражением.
-func (s *grepService) matchLine(pattern *regexp.Regexp, line []byte, opts models.GrepOptions) bool {
-	match := pattern.Match(line)
-	return match != opts.Invert
+// matchLine - проверка совпадения строки с шаблоном с учетом -v.
+func (s *grepService) matchLine(m *matcher, line []byte, opts models.GrepOptions) bool {
+	return m.match(line) != opts.Invert
 }
 
 // getContextRange - получение диапазона строк для контекста.
@@ -156,3 +258,35 @@ func (s *grepService) getContextRange(pivot int, linesLen int, opts models.GrepO
 
 	return start, end
 }
+
+// startSpan - дочерний спан, только если родительский запрос трассируется:
+// без трассировки на чанк не тратятся лишние выделения памяти.
+func startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	parent := trace.SpanFromContext(ctx)
+	if !parent.IsRecording() {
+		return ctx, parent
+	}
+
+	return tracer.Start(ctx, name)
+}
+
+// newPatternError - ошибка компиляции шаблона с позицией ошибочного фрагмента.
+// Позиция - первое вхождение фрагмента в шаблон; 0, если фрагмента в шаблоне
+// нет (например, ошибка относится ко всему выражению с префиксом -i).
+func newPatternError(opts models.GrepOptions, err error) error {
+	perr := &models.PatternError{
+		Pattern: opts.Pattern,
+		Msg:     err.Error(),
+	}
+
+	var syntaxErr *syntax.Error
+	if errors.As(err, &syntaxErr) {
+		perr.Msg = syntaxErr.Code.String()
+		// в экранированном -F шаблоне позиции не совпадают с исходными
+		if off := strings.Index(opts.Pattern, syntaxErr.Expr); off >= 0 && syntaxErr.Expr != "" && !opts.Fixed {
+			perr.Pos = utf8.RuneCountInString(opts.Pattern[:off]) + 1
+		}
+	}
+
+	return perr
+}
@@ -1,7 +1,10 @@
 package grepsvc
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -19,6 +22,7 @@ func TestGrepService_ProcessChunk(t *testing.T) {
 		task     *models.Task
 		expected *models.Result
 		wantErr  bool
+		errIs    error
 	}{
 		{
 			name: "базовый поиск",
@@ -163,6 +167,22 @@ func TestGrepService_ProcessChunk(t *testing.T) {
 			},
 			expected: nil,
 			wantErr:  true,
+			errIs:    models.ErrInvalidPattern,
+		},
+		{
+			name: "фиксированная строка с некорректным UTF-8",
+			task: &models.Task{
+				Data:        []byte("a\xffb\n"),
+				Index:       0,
+				LineNumbers: []int64{1},
+				Options: models.GrepOptions{
+					Pattern: "\xff",
+					Fixed:   true,
+				},
+			},
+			expected: nil,
+			wantErr:  true,
+			errIs:    models.ErrInvalidPattern,
 		},
 	}
 
@@ -172,6 +192,9 @@ func TestGrepService_ProcessChunk(t *testing.T) {
 
 			if tt.wantErr {
 				assert.Error(t, err)
+				if tt.errIs != nil {
+					assert.ErrorIs(t, err, tt.errIs)
+				}
 				return
 			}
 
@@ -312,3 +335,334 @@ func TestGrepService_getContextRange(t *testing.T) {
 		})
 	}
 }
+
+func TestPatternCache(t *testing.T) {
+	t.Run("повторный запрос берется из кэша", func(t *testing.T) {
+		svc := &grepService{cache: newPatternCache(4)}
+		opts := models.GrepOptions{Pattern: "test"}
+
+		first, err := svc.getMatcher(context.Background(), opts)
+		require.NoError(t, err)
+		second, err := svc.getMatcher(context.Background(), opts)
+		require.NoError(t, err)
+
+		assert.Same(t, first, second)
+
+		stats := svc.PatternCacheStats()
+		assert.Equal(t, uint64(1), stats.Hits)
+		assert.Equal(t, uint64(1), stats.Misses)
+		assert.Equal(t, 1, stats.Size)
+	})
+
+	t.Run("флаги входят в ключ", func(t *testing.T) {
+		svc := &grepService{cache: newPatternCache(4)}
+
+		plain, err := svc.getMatcher(context.Background(), models.GrepOptions{Pattern: "a.b"})
+		require.NoError(t, err)
+		fixed, err := svc.getMatcher(context.Background(), models.GrepOptions{Pattern: "a.b", Fixed: true})
+		require.NoError(t, err)
+		ignoreCase, err := svc.getMatcher(context.Background(), models.GrepOptions{Pattern: "a.b", IgnoreCase: true})
+		require.NoError(t, err)
+
+		assert.NotSame(t, plain, fixed)
+		assert.NotSame(t, plain, ignoreCase)
+		assert.Equal(t, 3, svc.PatternCacheStats().Size)
+	})
+
+	t.Run("вытеснение самого старого шаблона", func(t *testing.T) {
+		svc := &grepService{cache: newPatternCache(2)}
+
+		for _, p := range []string{"a", "b", "a", "c"} {
+			_, err := svc.getMatcher(context.Background(), models.GrepOptions{Pattern: p})
+			require.NoError(t, err)
+		}
+
+		stats := svc.PatternCacheStats()
+		assert.Equal(t, 2, stats.Size)
+		assert.Equal(t, uint64(1), stats.Evictions)
+
+		// "b" вытеснен, "a" остался, так как использовался недавно
+		_, err := svc.getMatcher(context.Background(), models.GrepOptions{Pattern: "a"})
+		require.NoError(t, err)
+		assert.Equal(t, stats.Hits+1, svc.PatternCacheStats().Hits)
+
+		_, err = svc.getMatcher(context.Background(), models.GrepOptions{Pattern: "b"})
+		require.NoError(t, err)
+		assert.Equal(t, stats.Misses+1, svc.PatternCacheStats().Misses)
+	})
+
+	t.Run("невалидный шаблон не кэшируется", func(t *testing.T) {
+		svc := &grepService{cache: newPatternCache(2)}
+
+		_, err := svc.getMatcher(context.Background(), models.GrepOptions{Pattern: "[invalid"})
+		require.Error(t, err)
+		assert.Equal(t, 0, svc.PatternCacheStats().Size)
+	})
+
+	t.Run("конкурентный доступ", func(t *testing.T) {
+		svc := &grepService{cache: newPatternCache(8)}
+
+		var wg sync.WaitGroup
+		for i := range 64 {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				_, err := svc.getMatcher(context.Background(), models.GrepOptions{Pattern: fmt.Sprintf("p%d", i%16)})
+				assert.NoError(t, err)
+			}(i)
+		}
+		wg.Wait()
+
+		stats := svc.PatternCacheStats()
+		assert.LessOrEqual(t, stats.Size, 8)
+		assert.Equal(t, uint64(64), stats.Hits+stats.Misses)
+	})
+}
+
+// benchChunk - чанк для бенчмарков: lines строк, каждая десятая содержит шаблон.
+func benchChunk(lines int) *models.Task {
+	var buf bytes.Buffer
+	lineNumbers := make([]int64, lines)
+	for i := range lines {
+		if i%10 == 0 {
+			fmt.Fprintf(&buf, "2024-01-01 12:00:%02d ERROR request %d failed\n", i%60, i)
+		} else {
+			fmt.Fprintf(&buf, "2024-01-01 12:00:%02d INFO request %d ok\n", i%60, i)
+		}
+		lineNumbers[i] = int64(i + 1)
+	}
+
+	return &models.Task{
+		Data:        buf.Bytes(),
+		LineNumbers: lineNumbers,
+		Options:     models.GrepOptions{Pattern: `ERROR request \d+ (failed|timeout)`},
+	}
+}
+
+func BenchmarkGrepService_getPattern(b *testing.B) {
+	opts := models.GrepOptions{Pattern: `ERROR request \d+ (failed|timeout)`, IgnoreCase: true}
+
+	b.Run("compile", func(b *testing.B) {
+		svc := &grepService{}
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := svc.makePattern(opts); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("cached", func(b *testing.B) {
+		svc := &grepService{cache: newPatternCache(DefaultPatternCacheSize)}
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := svc.getMatcher(context.Background(), opts); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("cached_parallel", func(b *testing.B) {
+		svc := &grepService{cache: newPatternCache(DefaultPatternCacheSize)}
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				if _, err := svc.getMatcher(context.Background(), opts); err != nil {
+					b.Error(err)
+					return
+				}
+			}
+		})
+	})
+}
+
+// BenchmarkGrepService_ProcessChunk - много мелких чанков с одним шаблоном,
+// как при разбиении большого файла.
+func BenchmarkGrepService_ProcessChunk(b *testing.B) {
+	task := benchChunk(16)
+
+	b.Run("uncached", func(b *testing.B) {
+		// размер кэша 1 и чередование шаблонов дают промах на каждом чанке
+		svc := NewWithCacheSize(1)
+		other := *task
+		other.Options.Pattern = task.Options.Pattern + "|x"
+		b.ReportAllocs()
+		i := 0
+		for b.Loop() {
+			t := task
+			if i%2 == 1 {
+				t = &other
+			}
+			i++
+			if _, err := svc.ProcessChunk(context.Background(), t); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("cached", func(b *testing.B) {
+		svc := New()
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := svc.ProcessChunk(context.Background(), task); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// Тест сборки контекста при сканировании всего буфера.
+func TestGrepService_ProcessChunk_Context(t *testing.T) {
+	svc := New()
+
+	// строки: 1 a, 2 x, 3 b, 4 x, 5 c, 6 d, 7 e, 8 x
+	data := []byte("a\nx\nb\nx\nc\nd\ne\nx\n")
+	lineNumbers := []int64{1, 2, 3, 4, 5, 6, 7, 8}
+
+	tests := []struct {
+		name     string
+		opts     models.GrepOptions
+		expected []int64
+	}{
+		{name: "без контекста", opts: models.GrepOptions{Pattern: "x"}, expected: []int64{2, 4, 8}},
+		{name: "перекрывающийся -C 1", opts: models.GrepOptions{Pattern: "x", Around: 1}, expected: []int64{1, 2, 3, 4, 5, 7, 8}},
+		{name: "-B 2", opts: models.GrepOptions{Pattern: "x", Before: 2}, expected: []int64{1, 2, 3, 4, 6, 7, 8}},
+		{name: "-A 3 поглощает следующее совпадение", opts: models.GrepOptions{Pattern: "x", After: 3}, expected: []int64{2, 3, 4, 5, 6, 7, 8}},
+		{name: "-A за концом чанка", opts: models.GrepOptions{Pattern: "e", After: 5}, expected: []int64{7, 8}},
+		{name: "-v", opts: models.GrepOptions{Pattern: "x", Invert: true}, expected: []int64{1, 3, 5, 6, 7}},
+		{name: "-v -B 1", opts: models.GrepOptions{Pattern: "[a-e]", Invert: true, Before: 1}, expected: []int64{1, 2, 3, 4, 7, 8}},
+		{name: "пустая строка", opts: models.GrepOptions{Pattern: "^$"}, expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := svc.ProcessChunk(context.Background(), &models.Task{
+				Data:        data,
+				LineNumbers: lineNumbers,
+				Options:     tt.opts,
+			})
+			require.NoError(t, err)
+
+			var got []int64
+			for _, match := range result.Matches {
+				got = append(got, match.LineNumber)
+				assert.Equal(t, string(bytes.Split(data, []byte("\n"))[match.LineNumber-1]), string(match.Content))
+			}
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+
+	t.Run("номеров строк меньше, чем строк", func(t *testing.T) {
+		result, err := svc.ProcessChunk(context.Background(), &models.Task{
+			Data:        data,
+			LineNumbers: lineNumbers[:5],
+			Options:     models.GrepOptions{Pattern: "x", After: 1},
+		})
+		require.NoError(t, err)
+		require.Len(t, result.Matches, 4)
+		assert.Equal(t, int64(5), result.Matches[3].LineNumber)
+	})
+}
+
+// Несовпадающие строки не должны приводить к выделению памяти.
+func TestGrepService_ProcessChunk_Allocs(t *testing.T) {
+	svc := New()
+
+	for _, opts := range []models.GrepOptions{
+		{Pattern: "NOMATCH"},
+		{Pattern: `NO\d+MATCH`},
+		{Pattern: `request`, Invert: true},
+	} {
+		small := benchChunk(10)
+		large := benchChunk(10000)
+		small.Options, large.Options = opts, opts
+
+		// прогрев кэша шаблонов
+		_, err := svc.ProcessChunk(context.Background(), small)
+		require.NoError(t, err)
+
+		allocsSmall := testing.AllocsPerRun(10, func() {
+			_, _ = svc.ProcessChunk(context.Background(), small)
+		})
+		allocsLarge := testing.AllocsPerRun(10, func() {
+			_, _ = svc.ProcessChunk(context.Background(), large)
+		})
+
+		assert.Equal(t, allocsSmall, allocsLarge, "шаблон %q", opts.Pattern)
+	}
+}
+
+func BenchmarkGrepService_ProcessChunk_Scan(b *testing.B) {
+	svc := New()
+
+	cases := []struct {
+		name string
+		opts models.GrepOptions
+	}{
+		{name: "no_match", opts: models.GrepOptions{Pattern: "NOMATCH"}},
+		{name: "sparse_match", opts: models.GrepOptions{Pattern: `ERROR request \d+`}},
+		{name: "sparse_match_context", opts: models.GrepOptions{Pattern: `ERROR request \d+`, Around: 2}},
+		{name: "invert", opts: models.GrepOptions{Pattern: "INFO", Invert: true}},
+	}
+
+	for _, tc := range cases {
+		task := benchChunk(10000)
+		task.Options = tc.opts
+
+		b.Run(tc.name, func(b *testing.B) {
+			b.SetBytes(int64(len(task.Data)))
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := svc.ProcessChunk(context.Background(), task); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// Чанк больше окна сканирования должен давать тот же результат,
+// что и построчная проверка.
+func TestGrepService_ProcessChunk_MultiWindow(t *testing.T) {
+	svc := New()
+	task := benchChunk(3 * scanWindow / 40)
+	require.Greater(t, len(task.Data), 2*scanWindow)
+
+	for _, opts := range []models.GrepOptions{
+		{Pattern: `ERROR request \d+`},
+		{Pattern: `^$|request 1\d{4} ok`},
+		{Pattern: `ERROR`, Invert: true},
+	} {
+		task.Options = opts
+
+		re, err := svc.(*grepService).makePattern(opts)
+		require.NoError(t, err)
+
+		var expected []int64
+		for i, line := range bytes.Split(bytes.TrimSuffix(task.Data, []byte("\n")), []byte("\n")) {
+			if re.Match(line) != opts.Invert {
+				expected = append(expected, int64(i+1))
+			}
+		}
+
+		result, err := svc.ProcessChunk(context.Background(), task)
+		require.NoError(t, err)
+
+		got := make([]int64, 0, len(result.Matches))
+		for _, match := range result.Matches {
+			got = append(got, match.LineNumber)
+		}
+		assert.Equal(t, expected, got, "шаблон %q", opts.Pattern)
+	}
+}
+
+func TestGrepService_ProcessChunk_Cancelled(t *testing.T) {
+	svc := New()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := svc.ProcessChunk(ctx, benchChunk(10))
+	require.ErrorIs(t, err, models.ErrCancelled)
+	assert.ErrorIs(t, err, context.Canceled)
+}
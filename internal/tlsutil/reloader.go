@@ -0,0 +1,139 @@
+package tlsutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// reloadCheckInterval - как часто проверяется изменение файлов на диске.
+const reloadCheckInterval = time.Second
+
+// fileReloader - кэш содержимого файлов, перечитываемый при изменении mtime.
+// Проверка выполняется не чаще reloadCheckInterval.
+type fileReloader[T any] struct {
+	paths []string
+	load  func() (T, error)
+
+	mu        sync.Mutex
+	value     T
+	modTimes  []time.Time
+	checkedAt time.Time
+}
+
+func newFileReloader[T any](load func() (T, error), paths ...string) (*fileReloader[T], error) {
+	r := &fileReloader[T]{
+		paths: paths,
+		load:  load,
+	}
+
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// get - текущее значение; при изменении файлов значение перечитывается.
+// Если новые файлы некорректны (например, записаны не полностью),
+// продолжает использоваться предыдущее значение.
+func (r *fileReloader[T]) get() T {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if time.Since(r.checkedAt) < reloadCheckInterval {
+		return r.value
+	}
+	r.checkedAt = time.Now()
+
+	modTimes, err := r.stat()
+	if err != nil || equalTimes(modTimes, r.modTimes) {
+		return r.value
+	}
+
+	if value, err := r.load(); err == nil {
+		r.value = value
+		r.modTimes = modTimes
+	}
+
+	return r.value
+}
+
+func (r *fileReloader[T]) reload() error {
+	modTimes, err := r.stat()
+	if err != nil {
+		return err
+	}
+
+	value, err := r.load()
+	if err != nil {
+		return err
+	}
+
+	r.value = value
+	r.modTimes = modTimes
+	r.checkedAt = time.Now()
+
+	return nil
+}
+
+func (r *fileReloader[T]) stat() ([]time.Time, error) {
+	modTimes := make([]time.Time, len(r.paths))
+	for i, path := range r.paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("os.Stat %s: %w", path, err)
+		}
+		modTimes[i] = info.ModTime()
+	}
+
+	return modTimes, nil
+}
+
+func equalTimes(a, b []time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// newCertReloader - перезагружаемая пара сертификат/ключ.
+func newCertReloader(certFile, keyFile string) (*fileReloader[*tls.Certificate], error) {
+	return newFileReloader(func() (*tls.Certificate, error) {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("tls.LoadX509KeyPair: %w", err)
+		}
+		return &cert, nil
+	}, certFile, keyFile)
+}
+
+// newPoolReloader - перезагружаемый пул корневых сертификатов.
+func newPoolReloader(caFile string) (*fileReloader[*x509.CertPool], error) {
+	return newFileReloader(func() (*x509.CertPool, error) {
+		return loadPool(caFile)
+	}, caFile)
+}
+
+func loadPool(caFile string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("os.ReadFile %s: %w", caFile, err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("в %s нет PEM сертификатов", caFile)
+	}
+
+	return pool, nil
+}
@@ -0,0 +1,113 @@
+package tlsutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/sunr3d/quorum-grep/internal/config"
+)
+
+// ErrSubjectNotAllowed - сертификат клиента не входит в список разрешенных.
+var ErrSubjectNotAllowed = errors.New("субъект сертификата не разрешен")
+
+// NewServerConfig - TLS конфигурация сервера, nil если TLS не настроен.
+// Сертификат, ключ и CA клиентов перечитываются при изменении файлов.
+// Если задан ClientCAFile, сервер требует сертификат клиента (mTLS);
+// если задан AllowedSubjects, CN или DNS SAN клиента должен входить в список.
+func NewServerConfig(cfg config.ServerTLSConfig) (*tls.Config, error) {
+	if cfg.CertFile == "" && cfg.KeyFile == "" {
+		return nil, nil
+	}
+
+	certs, err := newCertReloader(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("newCertReloader: %w", err)
+	}
+
+	base := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return certs.get(), nil
+		},
+	}
+
+	if cfg.ClientCAFile == "" {
+		return base, nil
+	}
+
+	clientCAs, err := newPoolReloader(cfg.ClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("newPoolReloader: %w", err)
+	}
+
+	// конфигурация собирается на каждое соединение, чтобы подхватить новый CA
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			c := base.Clone()
+			c.ClientAuth = tls.RequireAndVerifyClientCert
+			c.ClientCAs = clientCAs.get()
+			if len(cfg.AllowedSubjects) > 0 {
+				c.VerifyConnection = func(state tls.ConnectionState) error {
+					return VerifySubject(state.PeerCertificates, cfg.AllowedSubjects)
+				}
+			}
+			return c, nil
+		},
+	}, nil
+}
+
+// NewClientConfig - TLS конфигурация клиента, nil если TLS выключен.
+// Без CAFile используются системные корневые сертификаты.
+func NewClientConfig(cfg config.ClientTLSConfig) (*tls.Config, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	c := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: cfg.ServerName,
+	}
+
+	if cfg.CAFile != "" {
+		pool, err := loadPool(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("loadPool: %w", err)
+		}
+		c.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		certs, err := newCertReloader(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("newCertReloader: %w", err)
+		}
+		c.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return certs.get(), nil
+		}
+	}
+
+	return c, nil
+}
+
+// VerifySubject - проверка, что CN или один из DNS SAN сертификата разрешен.
+func VerifySubject(chain []*x509.Certificate, allowed []string) error {
+	if len(chain) == 0 {
+		return fmt.Errorf("%w: сертификат не предъявлен", ErrSubjectNotAllowed)
+	}
+
+	leaf := chain[0]
+	if slices.Contains(allowed, leaf.Subject.CommonName) {
+		return nil
+	}
+	for _, name := range leaf.DNSNames {
+		if slices.Contains(allowed, name) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %q", ErrSubjectNotAllowed, leaf.Subject.CommonName)
+}
@@ -0,0 +1,245 @@
+package tlsutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sunr3d/quorum-grep/internal/config"
+)
+
+// testCA - самоподписанный CA для выпуска тестовых сертификатов.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	file string
+}
+
+func newTestCA(t *testing.T, dir string) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	file := filepath.Join(dir, "ca.pem")
+	writePEM(t, file, "CERTIFICATE", der)
+
+	return &testCA{cert: cert, key: key, file: file}
+}
+
+// issue - выпуск сертификата с заданным CN, возвращает пути к сертификату и ключу.
+func (ca *testCA) issue(t *testing.T, dir, cn string, serial int64) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: cn},
+		DNSNames:     []string{cn},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(dir, cn+".pem")
+	keyFile := filepath.Join(dir, cn+"-key.pem")
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+
+	return certFile, keyFile
+}
+
+func writePEM(t *testing.T, file, typ string, der []byte) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0o600))
+}
+
+// handshake - TLS рукопожатие клиента с сервером через loopback.
+// Возвращает сертификат сервера и ошибку рукопожатия со стороны сервера.
+func handshake(t *testing.T, serverCfg, clientCfg *tls.Config) (*x509.Certificate, error) {
+	t.Helper()
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", serverCfg)
+	require.NoError(t, err)
+	defer ln.Close()
+
+	srvErr := make(chan error, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			srvErr <- err
+			return
+		}
+		defer conn.Close()
+		srvErr <- conn.(*tls.Conn).Handshake()
+	}()
+
+	conn, err := tls.Dial("tcp", ln.Addr().String(), clientCfg)
+	if err != nil {
+		<-srvErr
+		return nil, err
+	}
+	defer conn.Close()
+
+	// в TLS 1.3 отказ сервера виден клиенту только при чтении
+	if err := <-srvErr; err != nil {
+		return nil, err
+	}
+
+	return conn.ConnectionState().PeerCertificates[0], nil
+}
+
+func TestNewServerConfig_Disabled(t *testing.T) {
+	cfg, err := NewServerConfig(config.ServerTLSConfig{})
+	require.NoError(t, err)
+	assert.Nil(t, cfg)
+
+	clientCfg, err := NewClientConfig(config.ClientTLSConfig{})
+	require.NoError(t, err)
+	assert.Nil(t, clientCfg)
+}
+
+func TestNewServerConfig_MissingFiles(t *testing.T) {
+	_, err := NewServerConfig(config.ServerTLSConfig{CertFile: "нет.pem", KeyFile: "нет-key.pem"})
+	assert.Error(t, err)
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, dir)
+	serverCert, serverKey := ca.issue(t, dir, "server", 2)
+	allowedCert, allowedKey := ca.issue(t, dir, "grep-client", 3)
+	otherCert, otherKey := ca.issue(t, dir, "intruder", 4)
+
+	serverCfg, err := NewServerConfig(config.ServerTLSConfig{
+		CertFile:        serverCert,
+		KeyFile:         serverKey,
+		ClientCAFile:    ca.file,
+		AllowedSubjects: []string{"grep-client"},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		cfg      config.ClientTLSConfig
+		expected bool
+	}{
+		{
+			name:     "разрешенный клиент",
+			cfg:      config.ClientTLSConfig{Enabled: true, CAFile: ca.file, CertFile: allowedCert, KeyFile: allowedKey, ServerName: "server"},
+			expected: true,
+		},
+		{
+			name: "клиент не из списка",
+			cfg:  config.ClientTLSConfig{Enabled: true, CAFile: ca.file, CertFile: otherCert, KeyFile: otherKey, ServerName: "server"},
+		},
+		{
+			name: "без клиентского сертификата",
+			cfg:  config.ClientTLSConfig{Enabled: true, CAFile: ca.file, ServerName: "server"},
+		},
+		{
+			name: "неверное имя сервера",
+			cfg:  config.ClientTLSConfig{Enabled: true, CAFile: ca.file, CertFile: allowedCert, KeyFile: allowedKey, ServerName: "other"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientCfg, err := NewClientConfig(tt.cfg)
+			require.NoError(t, err)
+
+			_, err = handshake(t, serverCfg, clientCfg)
+			if tt.expected {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestServerConfig_HotReload(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, dir)
+	certFile, keyFile := ca.issue(t, dir, "server", 2)
+
+	serverCfg, err := NewServerConfig(config.ServerTLSConfig{CertFile: certFile, KeyFile: keyFile})
+	require.NoError(t, err)
+
+	clientCfg, err := NewClientConfig(config.ClientTLSConfig{Enabled: true, CAFile: ca.file, ServerName: "server"})
+	require.NoError(t, err)
+
+	cert, err := handshake(t, serverCfg, clientCfg)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), cert.SerialNumber.Int64())
+
+	// перевыпуск сертификата с тем же именем файла
+	newCert, newKey := ca.issue(t, t.TempDir(), "server", 5)
+	for src, dst := range map[string]string{newCert: certFile, newKey: keyFile} {
+		data, err := os.ReadFile(src)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(dst, data, 0o600))
+		future := time.Now().Add(time.Minute)
+		require.NoError(t, os.Chtimes(dst, future, future))
+	}
+
+	assert.Eventually(t, func() bool {
+		cert, err := handshake(t, serverCfg, clientCfg)
+		return err == nil && cert.SerialNumber.Int64() == 5
+	}, 5*time.Second, 200*time.Millisecond)
+}
+
+func TestFileReloader_KeepsPreviousOnError(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, dir)
+	certFile, keyFile := ca.issue(t, dir, "server", 2)
+
+	r, err := newCertReloader(certFile, keyFile)
+	require.NoError(t, err)
+	before := r.get()
+
+	// файл перезаписан не полностью
+	require.NoError(t, os.WriteFile(certFile, []byte("broken"), 0o600))
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, future, future))
+	r.checkedAt = time.Time{}
+
+	assert.Same(t, before, r.get())
+}
@@ -0,0 +1,88 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+
+	"github.com/sunr3d/quorum-grep/internal/config"
+)
+
+// Поддерживаемые экспортеры спанов.
+const (
+	ExporterNone   = ""
+	ExporterStdout = "stdout"
+	ExporterStderr = "stderr"
+	ExporterFile   = "file"
+)
+
+// Enabled - включена ли трассировка.
+func Enabled(cfg config.TracingConfig) bool {
+	return cfg.Exporter != ExporterNone
+}
+
+// Setup - настройка глобального TracerProvider и W3C trace context.
+// Спаны пишутся в JSON в stdout, stderr или в файл cfg.File (file);
+// клиенту stdout не подходит - туда выводятся найденные строки. Возвращает функцию, которая дописывает
+// оставшиеся спаны и закрывает файл; без экспортера она ничего не делает.
+func Setup(cfg config.TracingConfig, service string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var out io.Writer
+	var file *os.File
+	switch cfg.Exporter {
+	case ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		out = os.Stdout
+	case ExporterStderr:
+		out = os.Stderr
+	case ExporterFile:
+		if cfg.File == "" {
+			return nil, fmt.Errorf("для экспортера %q не задан файл", ExporterFile)
+		}
+		f, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("os.OpenFile %s: %w", cfg.File, err)
+		}
+		out, file = f, f
+	default:
+		return nil, fmt.Errorf("неизвестный экспортер %q, ожидается %q, %q или %q",
+			cfg.Exporter, ExporterStdout, ExporterStderr, ExporterFile)
+	}
+
+	exporter, err := stdouttrace.New(stdouttrace.WithWriter(out))
+	if err != nil {
+		return nil, fmt.Errorf("stdouttrace.New: %w", err)
+	}
+
+	ratio := cfg.SampleRatio
+	if ratio <= 0 || ratio > 1 {
+		ratio = 1
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(service))),
+	)
+	otel.SetTracerProvider(tp)
+
+	return func(ctx context.Context) error {
+		err := tp.Shutdown(ctx)
+		if file != nil {
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		return err
+	}, nil
+}
@@ -0,0 +1,53 @@
+package tracing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+
+	"github.com/sunr3d/quorum-grep/internal/config"
+)
+
+func TestSetup(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.TracingConfig
+		wantErr bool
+	}{
+		{name: "выключена", cfg: config.TracingConfig{}},
+		{name: "неизвестный экспортер", cfg: config.TracingConfig{Exporter: "jaeger"}, wantErr: true},
+		{name: "file без файла", cfg: config.TracingConfig{Exporter: ExporterFile}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shutdown, err := Setup(tt.cfg, "test")
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.NoError(t, shutdown(context.Background()))
+		})
+	}
+}
+
+func TestSetup_File(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "trace.json")
+
+	shutdown, err := Setup(config.TracingConfig{Exporter: ExporterFile, File: file}, "test")
+	require.NoError(t, err)
+
+	_, span := otel.Tracer("test").Start(context.Background(), "test.span")
+	span.End()
+	require.NoError(t, shutdown(context.Background()))
+
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"Name":"test.span"`)
+}
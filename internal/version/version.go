@@ -0,0 +1,44 @@
+// Package version - версия и сведения о сборке.
+package version
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Version - версия, задается при сборке:
+// go build -ldflags "-X github.com/sunr3d/quorum-grep/internal/version.Version=v1.2.0"
+var Version = "dev"
+
+// Build - сведения о сборке.
+type Build struct {
+	Version string
+	// Commit, Time - коммит и его время из VCS, пусто при сборке не из git.
+	Commit string
+	Time   string
+	// Modified - собрано с незакоммиченными изменениями.
+	Modified  bool
+	GoVersion string
+}
+
+// Get - сведения о текущей сборке.
+func Get() Build {
+	b := Build{Version: Version, GoVersion: runtime.Version()}
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return b
+	}
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			b.Commit = s.Value
+		case "vcs.time":
+			b.Time = s.Value
+		case "vcs.modified":
+			b.Modified = s.Value == "true"
+		}
+	}
+
+	return b
+}
@@ -0,0 +1,113 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrInvalidPattern = errors.New("некорректный шаблон")
+	ErrLimitExceeded  = errors.New("превышен лимит")
+	ErrCancelled      = errors.New("обработка отменена")
+	ErrOverloaded     = errors.New("сервер перегружен")
+	ErrRateLimited    = errors.New("превышен лимит запросов")
+
+	ErrUnauthenticated  = errors.New("требуется аутентификация")
+	ErrPermissionDenied = errors.New("доступ запрещен")
+
+	ErrUnsupportedOptions = errors.New("неподдерживаемая комбинация опций")
+)
+
+// Причины ошибок, передаваемые в google.rpc.ErrorInfo.
+const (
+	ReasonInvalidPattern = "INVALID_PATTERN"
+	ReasonLimitExceeded  = "LIMIT_EXCEEDED"
+	ReasonCancelled      = "CANCELLED"
+	ReasonOverloaded     = "OVERLOADED"
+	ReasonRateLimited    = "RATE_LIMITED"
+
+	ReasonUnauthenticated  = "UNAUTHENTICATED"
+	ReasonPermissionDenied = "PERMISSION_DENIED"
+
+	ErrorDomain = "quorum-grep"
+)
+
+// ErrorReason - причина ошибки для передачи по сети, либо пустая строка.
+func ErrorReason(err error) string {
+	switch {
+	case errors.Is(err, ErrInvalidPattern):
+		return ReasonInvalidPattern
+	case errors.Is(err, ErrLimitExceeded):
+		return ReasonLimitExceeded
+	case errors.Is(err, ErrCancelled):
+		return ReasonCancelled
+	case errors.Is(err, ErrOverloaded):
+		return ReasonOverloaded
+	case errors.Is(err, ErrRateLimited):
+		return ReasonRateLimited
+	case errors.Is(err, ErrUnauthenticated):
+		return ReasonUnauthenticated
+	case errors.Is(err, ErrPermissionDenied):
+		return ReasonPermissionDenied
+	default:
+		return ""
+	}
+}
+
+// ErrorByReason - типизированная ошибка по причине, либо nil.
+func ErrorByReason(reason string) error {
+	switch reason {
+	case ReasonInvalidPattern:
+		return ErrInvalidPattern
+	case ReasonLimitExceeded:
+		return ErrLimitExceeded
+	case ReasonCancelled:
+		return ErrCancelled
+	case ReasonOverloaded:
+		return ErrOverloaded
+	case ReasonRateLimited:
+		return ErrRateLimited
+	case ReasonUnauthenticated:
+		return ErrUnauthenticated
+	case ReasonPermissionDenied:
+		return ErrPermissionDenied
+	default:
+		return nil
+	}
+}
+
+// PatternError - ошибка компиляции шаблона.
+type PatternError struct {
+	Pattern string
+	// Pos - позиция ошибочного фрагмента в шаблоне (с 1), 0 если неизвестна.
+	Pos int
+	Msg string
+}
+
+func (e *PatternError) Error() string {
+	if e.Pos > 0 {
+		return fmt.Sprintf("%s %q: %s (позиция %d)", ErrInvalidPattern, e.Pattern, e.Msg, e.Pos)
+	}
+
+	return fmt.Sprintf("%s %q: %s", ErrInvalidPattern, e.Pattern, e.Msg)
+}
+
+func (e *PatternError) Is(target error) bool {
+	return target == ErrInvalidPattern
+}
+
+// RateLimitError - запрос отклонен лимитом запросов клиента.
+type RateLimitError struct {
+	ClientID string
+	// RetryAfter - через сколько появится свободный токен.
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%s для клиента %q, повторите через %s", ErrRateLimited, e.ClientID, e.RetryAfter)
+}
+
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
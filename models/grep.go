@@ -15,4 +15,14 @@ type GrepOptions struct {
 type GrepConfig struct {
 	Options GrepOptions
 	Files   []string
+	// Stats - вывести статистику поиска в stderr.
+	Stats bool
+	// Quorum - переопределение QUORUM: majority, all, weighted или число чанков.
+	Quorum string
+	// LocalFallback - обрабатывать локально чанки, не обработанные серверами.
+	LocalFallback bool
+	// LocalCluster - сколько серверов запустить в процессе, 0 - серверы из конфига.
+	LocalCluster int
+	// Format - формат вывода: text, json, csv, sarif или шаблон text/template.
+	Format string
 }
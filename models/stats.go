@@ -0,0 +1,17 @@
+package models
+
+type PatternCacheStats struct {
+	Size      int
+	Capacity  int
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+}
+
+// Capabilities - что умеет сервис поиска.
+type Capabilities struct {
+	// Engines - способы поиска.
+	Engines []string
+	// Options - поддерживаемые опции поиска (имена полей GrepOptions в proto).
+	Options []string
+}
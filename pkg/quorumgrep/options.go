@@ -0,0 +1,159 @@
+package quorumgrep
+
+import (
+	"time"
+
+	"github.com/sunr3d/quorum-grep/internal/config"
+	"github.com/sunr3d/quorum-grep/internal/discovery"
+)
+
+// Option - настройка Searcher.
+type Option func(*settings)
+
+type settings struct {
+	cfg    config.ClientConfig
+	quorum int
+}
+
+// defaultSettings - значения по умолчанию, как у mygrep без config.yaml.
+func defaultSettings() *settings {
+	return &settings{cfg: config.ClientConfig{
+		ServerList: []string{"localhost:50051", "localhost:50052", "localhost:50053"},
+		Timeout:    "30s",
+		ChunkSize:  1024,
+		HealthCheck: config.HealthCheckConfig{
+			Enabled:  true,
+			Interval: "5s",
+			Timeout:  "1s",
+		},
+	}}
+}
+
+// WithServers - неизменный список серверов host:port.
+func WithServers(servers ...string) Option {
+	return func(s *settings) {
+		s.cfg.ServerList = servers
+		s.cfg.Discovery = config.DiscoveryConfig{Mode: discovery.ModeStatic}
+	}
+}
+
+// WithDNS - серверы из A/AAAA записей target (host:port), список
+// обновляется каждые refresh. Кворум считается от текущего числа серверов.
+func WithDNS(target string, refresh time.Duration) Option {
+	return func(s *settings) {
+		s.cfg.Discovery = config.DiscoveryConfig{Mode: discovery.ModeDNS, Target: target, Refresh: refresh.String()}
+	}
+}
+
+// WithSRV - серверы из SRV записей name (_service._proto.domain).
+func WithSRV(name string, refresh time.Duration) Option {
+	return func(s *settings) {
+		s.cfg.Discovery = config.DiscoveryConfig{Mode: discovery.ModeSRV, Target: name, Refresh: refresh.String()}
+	}
+}
+
+// WithServersFile - серверы из файла, по одному host:port на строку;
+// файл перечитывается при изменении.
+func WithServersFile(path string) Option {
+	return func(s *settings) {
+		s.cfg.Discovery = config.DiscoveryConfig{Mode: discovery.ModeFile, File: path}
+	}
+}
+
+// WithQuorum - сколько чанков должно быть обработано успешно,
+// по умолчанию большинство текущих серверов.
+func WithQuorum(n int) Option {
+	return func(s *settings) {
+		s.quorum = n
+	}
+}
+
+// WithTimeout - бюджет одного поиска, включая чтение входа и все попытки.
+func WithTimeout(d time.Duration) Option {
+	return func(s *settings) {
+		s.cfg.Timeout = d.String()
+	}
+}
+
+// WithChunkSize - размер чанка в строках, 0 - вход делится поровну между серверами.
+func WithChunkSize(lines int) Option {
+	return func(s *settings) {
+		s.cfg.ChunkSize = lines
+	}
+}
+
+// WithHealthCheck - проверка серверов по grpc.health.v1 перед поиском,
+// результат кэшируется на interval. Нулевой interval выключает проверку.
+func WithHealthCheck(interval, timeout time.Duration) Option {
+	return func(s *settings) {
+		s.cfg.HealthCheck = config.HealthCheckConfig{
+			Enabled:  interval > 0,
+			Interval: interval.String(),
+			Timeout:  timeout.String(),
+		}
+	}
+}
+
+// WithHedging - дублирующий запрос на другой сервер, если ответа нет дольше delay.
+func WithHedging(delay time.Duration, maxHedges int) Option {
+	return func(s *settings) {
+		s.cfg.Hedge = config.HedgeConfig{
+			Enabled:   true,
+			Delay:     delay.String(),
+			MaxHedges: maxHedges,
+		}
+	}
+}
+
+// WithSpotChecks - локальная проверка доли ratio успешных чанков с
+// репутацией серверов в stateFile (пусто - в каталоге кэша пользователя).
+// Сервер, чьи ответы расходятся с локальным результатом больше чем в 10%
+// из не менее 5 проверок, исключается на час. Репутация сохраняется в Close.
+func WithSpotChecks(ratio float64, stateFile string) Option {
+	return func(s *settings) {
+		s.cfg.Verify = config.VerifyConfig{
+			Enabled:         true,
+			SpotCheckRatio:  ratio,
+			StateFile:       stateFile,
+			MaxDisagreement: 0.1,
+			MinChecks:       5,
+			Quarantine:      "1h",
+		}
+	}
+}
+
+// WithLocalFallback - обрабатывать в процессе чанки, которые не обработали
+// серверы, вместо ошибки ErrNoQuorum; autoMaxBytes > 0 - только для входа
+// не больше стольких байт.
+func WithLocalFallback(autoMaxBytes int64) Option {
+	return func(s *settings) {
+		s.cfg.Fallback = config.FallbackConfig{Enabled: autoMaxBytes <= 0, AutoMaxBytes: autoMaxBytes}
+	}
+}
+
+// WithTLS - TLS до серверов. caFile - CA серверов, пусто - системные корневые;
+// certFile и keyFile - сертификат клиента для mTLS, могут быть пустыми.
+func WithTLS(caFile, certFile, keyFile string) Option {
+	return func(s *settings) {
+		s.cfg.TLS = config.ClientTLSConfig{
+			Enabled:  true,
+			CAFile:   caFile,
+			CertFile: certFile,
+			KeyFile:  keyFile,
+		}
+	}
+}
+
+// WithAuthToken - bearer токен для серверов с аутентификацией.
+func WithAuthToken(token string) Option {
+	return func(s *settings) {
+		s.cfg.AuthToken = token
+	}
+}
+
+// WithClientID - идентификатор клиента для лимитов серверов, по умолчанию user@host.
+func WithClientID(id string) Option {
+	return func(s *settings) {
+		s.cfg.ClientID = id
+	}
+}
@@ -0,0 +1,131 @@
+// Package quorumgrep - распределенный поиск на кластере серверов quorum-grep
+// из кода на Go, без запуска mygrep.
+package quorumgrep
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+
+	"github.com/sunr3d/quorum-grep/internal/client"
+	"github.com/sunr3d/quorum-grep/internal/config"
+	"github.com/sunr3d/quorum-grep/models"
+)
+
+// Ошибки поиска, проверяются через errors.Is.
+var (
+	// ErrNoQuorum - успешно ответивших или доступных серверов меньше кворума.
+	ErrNoQuorum = client.ErrNoQuorum
+	// ErrInvalidPattern - шаблон не компилируется.
+	ErrInvalidPattern = models.ErrInvalidPattern
+	// ErrUnsupportedOptions - некорректная комбинация опций.
+	ErrUnsupportedOptions = models.ErrUnsupportedOptions
+	// ErrLimitExceeded - вход превышает лимиты серверов.
+	ErrLimitExceeded = models.ErrLimitExceeded
+	// ErrUnauthenticated, ErrPermissionDenied - отказ аутентификации на серверах.
+	ErrUnauthenticated  = models.ErrUnauthenticated
+	ErrPermissionDenied = models.ErrPermissionDenied
+)
+
+// Options - опции одного поиска, аналог флагов mygrep.
+type Options struct {
+	Pattern    string
+	IgnoreCase bool
+	// Invert - выбирать строки, не содержащие шаблон.
+	Invert bool
+	// Fixed - шаблон - фиксированная строка, а не регулярное выражение.
+	Fixed bool
+	// Before, After, Context - строки контекста до, после и вокруг найденной.
+	Before  int
+	After   int
+	Context int
+}
+
+// Match - найденная строка или строка контекста.
+type Match struct {
+	// Line - номер строки с 1.
+	Line int64
+	// Offset - смещение начала строки от начала входа в байтах.
+	Offset int64
+	// Text - строка без перевода строки.
+	Text []byte
+	// Context - строка контекста, а не выбранная поиском.
+	Context bool
+	// Submatches - границы [start, end) совпадений шаблона в Text.
+	Submatches [][]int
+}
+
+// Searcher - поиск на кластере. Безопасен для одновременного использования;
+// соединения с серверами общие для всех поисков и закрываются в Close.
+type Searcher struct {
+	client *client.Client
+}
+
+// New - конструктор Searcher.
+func New(opts ...Option) (*Searcher, error) {
+	s := defaultSettings()
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	cli, err := client.New(&config.Config{Client: s.cfg})
+	if err != nil {
+		return nil, fmt.Errorf("client.New: %w", err)
+	}
+	cli.DisableHistory()
+
+	if s.quorum != 0 {
+		if err := cli.SetQuorum(s.quorum); err != nil {
+			cli.Close()
+			return nil, fmt.Errorf("client.SetQuorum: %w", err)
+		}
+	}
+
+	return &Searcher{client: cli}, nil
+}
+
+// Close - закрывает соединения с серверами и сохраняет репутацию
+// серверов (WithSpotChecks).
+func (s *Searcher) Close() error {
+	return s.client.Close()
+}
+
+// Search - поиск в r. Вход читается целиком и обрабатывается на серверах
+// до возврата, поэтому ошибки чтения и кворума возвращаются сразу, а
+// итератор выдает строки по возрастанию номера. Границы совпадений
+// вычисляются по мере обхода.
+func (s *Searcher) Search(ctx context.Context, r io.Reader, opts Options) (iter.Seq[Match], error) {
+	res, err := s.client.Search(ctx, "", r, models.GrepOptions{
+		Pattern:    opts.Pattern,
+		After:      opts.After,
+		Before:     opts.Before,
+		Around:     opts.Context,
+		IgnoreCase: opts.IgnoreCase,
+		Invert:     opts.Invert,
+		Fixed:      opts.Fixed,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return func(yield func(Match) bool) {
+		// ошибка Each - только остановка обхода: шаблон уже проверен в Search
+		_ = res.Each(func(line client.OutputLine) error {
+			if !yield(Match{
+				Line:       line.Number,
+				Offset:     line.Offset,
+				Text:       line.Text,
+				Context:    !line.Match,
+				Submatches: line.Submatches,
+			}) {
+				return errStop
+			}
+			return nil
+		})
+	}, nil
+}
+
+// errStop - остановка обхода по запросу вызывающего.
+var errStop = errors.New("обход остановлен")
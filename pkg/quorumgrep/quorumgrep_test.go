@@ -0,0 +1,220 @@
+package quorumgrep
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	grpchandlers "github.com/sunr3d/quorum-grep/internal/handlers/grpc"
+	"github.com/sunr3d/quorum-grep/internal/services/grepsvc"
+	pbg "github.com/sunr3d/quorum-grep/proto/grepsvc"
+)
+
+// startServers - n серверов GrepService на loopback.
+func startServers(t *testing.T, n int) []string {
+	t.Helper()
+
+	addrs := make([]string, n)
+	for i := range n {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+
+		srv := grpc.NewServer()
+		pbg.RegisterGrepServiceServer(srv, grpchandlers.New(grepsvc.New()))
+		go srv.Serve(lis)
+		t.Cleanup(srv.Stop)
+
+		addrs[i] = lis.Addr().String()
+	}
+
+	return addrs
+}
+
+func TestSearch(t *testing.T) {
+	servers := startServers(t, 3)
+	input := "ok\nfoo error\nmid\nbar\nERROR again\nend\n"
+
+	s, err := New(WithServers(servers...), WithTimeout(5*time.Second), WithChunkSize(2))
+	require.NoError(t, err)
+	defer s.Close()
+
+	tests := []struct {
+		name     string
+		opts     Options
+		expected []Match
+	}{
+		{
+			name: "регулярное выражение",
+			opts: Options{Pattern: "err.r"},
+			expected: []Match{
+				{Line: 2, Offset: 3, Text: []byte("foo error"), Submatches: [][]int{{4, 9}}},
+			},
+		},
+		{
+			name: "игнорирование регистра и контекст",
+			opts: Options{Pattern: "error", IgnoreCase: true, Before: 1},
+			expected: []Match{
+				{Line: 1, Offset: 0, Text: []byte("ok"), Context: true},
+				{Line: 2, Offset: 3, Text: []byte("foo error"), Submatches: [][]int{{4, 9}}},
+				{Line: 4, Offset: 17, Text: []byte("bar"), Context: true},
+				{Line: 5, Offset: 21, Text: []byte("ERROR again"), Submatches: [][]int{{0, 5}}},
+			},
+		},
+		{
+			name:     "нет совпадений",
+			opts:     Options{Pattern: "nothing", Fixed: true},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seq, err := s.Search(context.Background(), strings.NewReader(input), tt.opts)
+			require.NoError(t, err)
+
+			var got []Match
+			for m := range seq {
+				got = append(got, m)
+			}
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestSearchStop(t *testing.T) {
+	s, err := New(WithServers(startServers(t, 2)...))
+	require.NoError(t, err)
+	defer s.Close()
+
+	seq, err := s.Search(context.Background(), strings.NewReader("a\na\na\n"), Options{Pattern: "a"})
+	require.NoError(t, err)
+
+	n := 0
+	for range seq {
+		n++
+		break
+	}
+	assert.Equal(t, 1, n)
+}
+
+func TestSearchErrors(t *testing.T) {
+	servers := startServers(t, 1)
+
+	tests := []struct {
+		name     string
+		servers  []string
+		quorum   int
+		opts     Options
+		expected error
+	}{
+		{name: "некорректный шаблон", servers: servers, opts: Options{Pattern: "("}, expected: ErrInvalidPattern},
+		{name: "отрицательный контекст", servers: servers, opts: Options{Pattern: "a", After: -1}, expected: ErrUnsupportedOptions},
+		{
+			name:     "кворум не достигнут",
+			servers:  []string{servers[0], "127.0.0.1:1"},
+			quorum:   2,
+			opts:     Options{Pattern: "a"},
+			expected: ErrNoQuorum,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := []Option{WithServers(tt.servers...), WithTimeout(2 * time.Second)}
+			if tt.quorum > 0 {
+				opts = append(opts, WithQuorum(tt.quorum))
+			}
+			s, err := New(opts...)
+			require.NoError(t, err)
+			defer s.Close()
+
+			_, err = s.Search(context.Background(), strings.NewReader("a\nb\n"), tt.opts)
+			assert.True(t, errors.Is(err, tt.expected), "%v", err)
+		})
+	}
+}
+
+func TestSearchLocalFallback(t *testing.T) {
+	servers := startServers(t, 1)
+	// один из трех серверов доступен: кворума нет
+	list := []string{servers[0], "127.0.0.1:1", "127.0.0.1:2"}
+	input := "ok\nfoo error\nmid\nbar error\n"
+
+	tests := []struct {
+		name     string
+		servers  []string
+		fallback int64
+		wantErr  error
+	}{
+		{name: "без локальной обработки", servers: list, wantErr: ErrNoQuorum},
+		{name: "локальная обработка", servers: list},
+		{name: "все серверы недоступны", servers: list[1:]},
+		{name: "автоматически для маленького входа", servers: list, fallback: 1024},
+		{name: "вход больше порога", servers: list, fallback: 8, wantErr: ErrNoQuorum},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := []Option{WithServers(tt.servers...), WithTimeout(2 * time.Second), WithChunkSize(1)}
+			if tt.wantErr == nil || tt.fallback > 0 {
+				opts = append(opts, WithLocalFallback(tt.fallback))
+			}
+			s, err := New(opts...)
+			require.NoError(t, err)
+			defer s.Close()
+
+			seq, err := s.Search(context.Background(), strings.NewReader(input), Options{Pattern: "error"})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			var lines []int64
+			for m := range seq {
+				lines = append(lines, m.Line)
+			}
+			assert.Equal(t, []int64{2, 4}, lines)
+		})
+	}
+}
+
+func TestSearchMembershipChange(t *testing.T) {
+	servers := startServers(t, 1)
+	file := filepath.Join(t.TempDir(), "servers")
+	require.NoError(t, os.WriteFile(file, []byte(servers[0]+"\n"), 0o600))
+
+	s, err := New(WithServersFile(file), WithTimeout(2*time.Second))
+	require.NoError(t, err)
+	defer s.Close()
+
+	_, err = s.Search(context.Background(), strings.NewReader("a\n"), Options{Pattern: "a"})
+	require.NoError(t, err)
+
+	// два недоступных сервера: кворум 2 из 3, доступен один
+	data := servers[0] + "\n127.0.0.1:1\n127.0.0.1:2\n"
+	require.NoError(t, os.WriteFile(file, []byte(data), 0o600))
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(file, future, future))
+	time.Sleep(1100 * time.Millisecond)
+
+	_, err = s.Search(context.Background(), strings.NewReader("a\n"), Options{Pattern: "a"})
+	assert.ErrorIs(t, err, ErrNoQuorum)
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(WithServers())
+	assert.Error(t, err)
+
+	_, err = New(WithServers("a:1", "b:1"), WithQuorum(3))
+	assert.Error(t, err)
+}
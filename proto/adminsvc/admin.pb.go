@@ -0,0 +1,886 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        v3.21.12
+// source: api/admin_service/admin.proto
+
+package adminsvc
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetInfoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetInfoRequest) Reset() {
+	*x = GetInfoRequest{}
+	mi := &file_api_admin_service_admin_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetInfoRequest) ProtoMessage() {}
+
+func (x *GetInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_admin_service_admin_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetInfoRequest.ProtoReflect.Descriptor instead.
+func (*GetInfoRequest) Descriptor() ([]byte, []int) {
+	return file_api_admin_service_admin_proto_rawDescGZIP(), []int{0}
+}
+
+type ServerInfo struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Version   string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	Commit    string                 `protobuf:"bytes,2,opt,name=commit,proto3" json:"commit,omitempty"`
+	BuildTime string                 `protobuf:"bytes,3,opt,name=build_time,json=buildTime,proto3" json:"build_time,omitempty"`
+	GoVersion string                 `protobuf:"bytes,4,opt,name=go_version,json=goVersion,proto3" json:"go_version,omitempty"`
+	// engines - способы поиска, options - поддерживаемые поля GrepOptions.
+	Engines       []string `protobuf:"bytes,5,rep,name=engines,proto3" json:"engines,omitempty"`
+	Options       []string `protobuf:"bytes,6,rep,name=options,proto3" json:"options,omitempty"`
+	Limits        *Limits  `protobuf:"bytes,7,opt,name=limits,proto3" json:"limits,omitempty"`
+	Tls           bool     `protobuf:"varint,8,opt,name=tls,proto3" json:"tls,omitempty"`
+	Auth          bool     `protobuf:"varint,9,opt,name=auth,proto3" json:"auth,omitempty"`
+	Faults        bool     `protobuf:"varint,10,opt,name=faults,proto3" json:"faults,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServerInfo) Reset() {
+	*x = ServerInfo{}
+	mi := &file_api_admin_service_admin_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServerInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerInfo) ProtoMessage() {}
+
+func (x *ServerInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_api_admin_service_admin_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerInfo.ProtoReflect.Descriptor instead.
+func (*ServerInfo) Descriptor() ([]byte, []int) {
+	return file_api_admin_service_admin_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ServerInfo) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *ServerInfo) GetCommit() string {
+	if x != nil {
+		return x.Commit
+	}
+	return ""
+}
+
+func (x *ServerInfo) GetBuildTime() string {
+	if x != nil {
+		return x.BuildTime
+	}
+	return ""
+}
+
+func (x *ServerInfo) GetGoVersion() string {
+	if x != nil {
+		return x.GoVersion
+	}
+	return ""
+}
+
+func (x *ServerInfo) GetEngines() []string {
+	if x != nil {
+		return x.Engines
+	}
+	return nil
+}
+
+func (x *ServerInfo) GetOptions() []string {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+func (x *ServerInfo) GetLimits() *Limits {
+	if x != nil {
+		return x.Limits
+	}
+	return nil
+}
+
+func (x *ServerInfo) GetTls() bool {
+	if x != nil {
+		return x.Tls
+	}
+	return false
+}
+
+func (x *ServerInfo) GetAuth() bool {
+	if x != nil {
+		return x.Auth
+	}
+	return false
+}
+
+func (x *ServerInfo) GetFaults() bool {
+	if x != nil {
+		return x.Faults
+	}
+	return false
+}
+
+// Limits - действующие лимиты сервера, 0 - без лимита.
+type Limits struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	MaxMessageSize      int64                  `protobuf:"varint,1,opt,name=max_message_size,json=maxMessageSize,proto3" json:"max_message_size,omitempty"`
+	MaxResponseSize     int64                  `protobuf:"varint,2,opt,name=max_response_size,json=maxResponseSize,proto3" json:"max_response_size,omitempty"`
+	MaxChunkDurationMs  int64                  `protobuf:"varint,3,opt,name=max_chunk_duration_ms,json=maxChunkDurationMs,proto3" json:"max_chunk_duration_ms,omitempty"`
+	MaxConcurrentChunks int64                  `protobuf:"varint,4,opt,name=max_concurrent_chunks,json=maxConcurrentChunks,proto3" json:"max_concurrent_chunks,omitempty"`
+	MaxQueuedPerClient  int64                  `protobuf:"varint,5,opt,name=max_queued_per_client,json=maxQueuedPerClient,proto3" json:"max_queued_per_client,omitempty"`
+	RateLimit           float64                `protobuf:"fixed64,6,opt,name=rate_limit,json=rateLimit,proto3" json:"rate_limit,omitempty"`
+	RateBurst           int64                  `protobuf:"varint,7,opt,name=rate_burst,json=rateBurst,proto3" json:"rate_burst,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *Limits) Reset() {
+	*x = Limits{}
+	mi := &file_api_admin_service_admin_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Limits) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Limits) ProtoMessage() {}
+
+func (x *Limits) ProtoReflect() protoreflect.Message {
+	mi := &file_api_admin_service_admin_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Limits.ProtoReflect.Descriptor instead.
+func (*Limits) Descriptor() ([]byte, []int) {
+	return file_api_admin_service_admin_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Limits) GetMaxMessageSize() int64 {
+	if x != nil {
+		return x.MaxMessageSize
+	}
+	return 0
+}
+
+func (x *Limits) GetMaxResponseSize() int64 {
+	if x != nil {
+		return x.MaxResponseSize
+	}
+	return 0
+}
+
+func (x *Limits) GetMaxChunkDurationMs() int64 {
+	if x != nil {
+		return x.MaxChunkDurationMs
+	}
+	return 0
+}
+
+func (x *Limits) GetMaxConcurrentChunks() int64 {
+	if x != nil {
+		return x.MaxConcurrentChunks
+	}
+	return 0
+}
+
+func (x *Limits) GetMaxQueuedPerClient() int64 {
+	if x != nil {
+		return x.MaxQueuedPerClient
+	}
+	return 0
+}
+
+func (x *Limits) GetRateLimit() float64 {
+	if x != nil {
+		return x.RateLimit
+	}
+	return 0
+}
+
+func (x *Limits) GetRateBurst() int64 {
+	if x != nil {
+		return x.RateBurst
+	}
+	return 0
+}
+
+type GetStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatsRequest) Reset() {
+	*x = GetStatsRequest{}
+	mi := &file_api_admin_service_admin_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatsRequest) ProtoMessage() {}
+
+func (x *GetStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_admin_service_admin_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetStatsRequest) Descriptor() ([]byte, []int) {
+	return file_api_admin_service_admin_proto_rawDescGZIP(), []int{3}
+}
+
+type ServerStats struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	UptimeMs int64                  `protobuf:"varint,1,opt,name=uptime_ms,json=uptimeMs,proto3" json:"uptime_ms,omitempty"`
+	// in_flight - чанки в обработке, включая ожидающие слота.
+	InFlight           int64  `protobuf:"varint,2,opt,name=in_flight,json=inFlight,proto3" json:"in_flight,omitempty"`
+	Chunks             int64  `protobuf:"varint,3,opt,name=chunks,proto3" json:"chunks,omitempty"`
+	FailedChunks       int64  `protobuf:"varint,4,opt,name=failed_chunks,json=failedChunks,proto3" json:"failed_chunks,omitempty"`
+	ScannedBytes       int64  `protobuf:"varint,5,opt,name=scanned_bytes,json=scannedBytes,proto3" json:"scanned_bytes,omitempty"`
+	ScannedLines       int64  `protobuf:"varint,6,opt,name=scanned_lines,json=scannedLines,proto3" json:"scanned_lines,omitempty"`
+	Matches            int64  `protobuf:"varint,7,opt,name=matches,proto3" json:"matches,omitempty"`
+	Draining           bool   `protobuf:"varint,8,opt,name=draining,proto3" json:"draining,omitempty"`
+	PatternCacheSize   int64  `protobuf:"varint,9,opt,name=pattern_cache_size,json=patternCacheSize,proto3" json:"pattern_cache_size,omitempty"`
+	PatternCacheHits   uint64 `protobuf:"varint,10,opt,name=pattern_cache_hits,json=patternCacheHits,proto3" json:"pattern_cache_hits,omitempty"`
+	PatternCacheMisses uint64 `protobuf:"varint,11,opt,name=pattern_cache_misses,json=patternCacheMisses,proto3" json:"pattern_cache_misses,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *ServerStats) Reset() {
+	*x = ServerStats{}
+	mi := &file_api_admin_service_admin_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServerStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerStats) ProtoMessage() {}
+
+func (x *ServerStats) ProtoReflect() protoreflect.Message {
+	mi := &file_api_admin_service_admin_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerStats.ProtoReflect.Descriptor instead.
+func (*ServerStats) Descriptor() ([]byte, []int) {
+	return file_api_admin_service_admin_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ServerStats) GetUptimeMs() int64 {
+	if x != nil {
+		return x.UptimeMs
+	}
+	return 0
+}
+
+func (x *ServerStats) GetInFlight() int64 {
+	if x != nil {
+		return x.InFlight
+	}
+	return 0
+}
+
+func (x *ServerStats) GetChunks() int64 {
+	if x != nil {
+		return x.Chunks
+	}
+	return 0
+}
+
+func (x *ServerStats) GetFailedChunks() int64 {
+	if x != nil {
+		return x.FailedChunks
+	}
+	return 0
+}
+
+func (x *ServerStats) GetScannedBytes() int64 {
+	if x != nil {
+		return x.ScannedBytes
+	}
+	return 0
+}
+
+func (x *ServerStats) GetScannedLines() int64 {
+	if x != nil {
+		return x.ScannedLines
+	}
+	return 0
+}
+
+func (x *ServerStats) GetMatches() int64 {
+	if x != nil {
+		return x.Matches
+	}
+	return 0
+}
+
+func (x *ServerStats) GetDraining() bool {
+	if x != nil {
+		return x.Draining
+	}
+	return false
+}
+
+func (x *ServerStats) GetPatternCacheSize() int64 {
+	if x != nil {
+		return x.PatternCacheSize
+	}
+	return 0
+}
+
+func (x *ServerStats) GetPatternCacheHits() uint64 {
+	if x != nil {
+		return x.PatternCacheHits
+	}
+	return 0
+}
+
+func (x *ServerStats) GetPatternCacheMisses() uint64 {
+	if x != nil {
+		return x.PatternCacheMisses
+	}
+	return 0
+}
+
+type DrainRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// wait - ответить, когда доработают все начатые чанки.
+	Wait          bool `protobuf:"varint,1,opt,name=wait,proto3" json:"wait,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DrainRequest) Reset() {
+	*x = DrainRequest{}
+	mi := &file_api_admin_service_admin_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DrainRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DrainRequest) ProtoMessage() {}
+
+func (x *DrainRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_admin_service_admin_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DrainRequest.ProtoReflect.Descriptor instead.
+func (*DrainRequest) Descriptor() ([]byte, []int) {
+	return file_api_admin_service_admin_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *DrainRequest) GetWait() bool {
+	if x != nil {
+		return x.Wait
+	}
+	return false
+}
+
+type DrainResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// in_flight - сколько чанков еще в обработке.
+	InFlight      int64 `protobuf:"varint,1,opt,name=in_flight,json=inFlight,proto3" json:"in_flight,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DrainResponse) Reset() {
+	*x = DrainResponse{}
+	mi := &file_api_admin_service_admin_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DrainResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DrainResponse) ProtoMessage() {}
+
+func (x *DrainResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_admin_service_admin_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DrainResponse.ProtoReflect.Descriptor instead.
+func (*DrainResponse) Descriptor() ([]byte, []int) {
+	return file_api_admin_service_admin_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DrainResponse) GetInFlight() int64 {
+	if x != nil {
+		return x.InFlight
+	}
+	return 0
+}
+
+// MethodFaults - неисправности методов, подходящих под шаблон method
+// (path.Match, например "/grepsvc.GrepService/*"). Вероятности от 0 до 1.
+type MethodFaults struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Method             string                 `protobuf:"bytes,1,opt,name=method,proto3" json:"method,omitempty"`
+	DelayMs            int64                  `protobuf:"varint,2,opt,name=delay_ms,json=delayMs,proto3" json:"delay_ms,omitempty"`
+	DelayProbability   float64                `protobuf:"fixed64,3,opt,name=delay_probability,json=delayProbability,proto3" json:"delay_probability,omitempty"`
+	DropProbability    float64                `protobuf:"fixed64,4,opt,name=drop_probability,json=dropProbability,proto3" json:"drop_probability,omitempty"`
+	ErrorProbability   float64                `protobuf:"fixed64,5,opt,name=error_probability,json=errorProbability,proto3" json:"error_probability,omitempty"`
+	CorruptProbability float64                `protobuf:"fixed64,6,opt,name=corrupt_probability,json=corruptProbability,proto3" json:"corrupt_probability,omitempty"`
+	OmitProbability    float64                `protobuf:"fixed64,7,opt,name=omit_probability,json=omitProbability,proto3" json:"omit_probability,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *MethodFaults) Reset() {
+	*x = MethodFaults{}
+	mi := &file_api_admin_service_admin_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MethodFaults) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MethodFaults) ProtoMessage() {}
+
+func (x *MethodFaults) ProtoReflect() protoreflect.Message {
+	mi := &file_api_admin_service_admin_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MethodFaults.ProtoReflect.Descriptor instead.
+func (*MethodFaults) Descriptor() ([]byte, []int) {
+	return file_api_admin_service_admin_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *MethodFaults) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *MethodFaults) GetDelayMs() int64 {
+	if x != nil {
+		return x.DelayMs
+	}
+	return 0
+}
+
+func (x *MethodFaults) GetDelayProbability() float64 {
+	if x != nil {
+		return x.DelayProbability
+	}
+	return 0
+}
+
+func (x *MethodFaults) GetDropProbability() float64 {
+	if x != nil {
+		return x.DropProbability
+	}
+	return 0
+}
+
+func (x *MethodFaults) GetErrorProbability() float64 {
+	if x != nil {
+		return x.ErrorProbability
+	}
+	return 0
+}
+
+func (x *MethodFaults) GetCorruptProbability() float64 {
+	if x != nil {
+		return x.CorruptProbability
+	}
+	return 0
+}
+
+func (x *MethodFaults) GetOmitProbability() float64 {
+	if x != nil {
+		return x.OmitProbability
+	}
+	return 0
+}
+
+type Faults struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Enabled       bool                   `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	Methods       []*MethodFaults        `protobuf:"bytes,2,rep,name=methods,proto3" json:"methods,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Faults) Reset() {
+	*x = Faults{}
+	mi := &file_api_admin_service_admin_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Faults) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Faults) ProtoMessage() {}
+
+func (x *Faults) ProtoReflect() protoreflect.Message {
+	mi := &file_api_admin_service_admin_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Faults.ProtoReflect.Descriptor instead.
+func (*Faults) Descriptor() ([]byte, []int) {
+	return file_api_admin_service_admin_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Faults) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *Faults) GetMethods() []*MethodFaults {
+	if x != nil {
+		return x.Methods
+	}
+	return nil
+}
+
+type GetFaultsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetFaultsRequest) Reset() {
+	*x = GetFaultsRequest{}
+	mi := &file_api_admin_service_admin_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetFaultsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetFaultsRequest) ProtoMessage() {}
+
+func (x *GetFaultsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_admin_service_admin_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetFaultsRequest.ProtoReflect.Descriptor instead.
+func (*GetFaultsRequest) Descriptor() ([]byte, []int) {
+	return file_api_admin_service_admin_proto_rawDescGZIP(), []int{9}
+}
+
+type SetFaultsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Faults        *Faults                `protobuf:"bytes,1,opt,name=faults,proto3" json:"faults,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetFaultsRequest) Reset() {
+	*x = SetFaultsRequest{}
+	mi := &file_api_admin_service_admin_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetFaultsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetFaultsRequest) ProtoMessage() {}
+
+func (x *SetFaultsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_admin_service_admin_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetFaultsRequest.ProtoReflect.Descriptor instead.
+func (*SetFaultsRequest) Descriptor() ([]byte, []int) {
+	return file_api_admin_service_admin_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SetFaultsRequest) GetFaults() *Faults {
+	if x != nil {
+		return x.Faults
+	}
+	return nil
+}
+
+var File_api_admin_service_admin_proto protoreflect.FileDescriptor
+
+const file_api_admin_service_admin_proto_rawDesc = "" +
+	"\n" +
+	"\x1dapi/admin_service/admin.proto\x12\badminsvc\"\x10\n" +
+	"\x0eGetInfoRequest\"\x98\x02\n" +
+	"\n" +
+	"ServerInfo\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\x12\x16\n" +
+	"\x06commit\x18\x02 \x01(\tR\x06commit\x12\x1d\n" +
+	"\n" +
+	"build_time\x18\x03 \x01(\tR\tbuildTime\x12\x1d\n" +
+	"\n" +
+	"go_version\x18\x04 \x01(\tR\tgoVersion\x12\x18\n" +
+	"\aengines\x18\x05 \x03(\tR\aengines\x12\x18\n" +
+	"\aoptions\x18\x06 \x03(\tR\aoptions\x12(\n" +
+	"\x06limits\x18\a \x01(\v2\x10.adminsvc.LimitsR\x06limits\x12\x10\n" +
+	"\x03tls\x18\b \x01(\bR\x03tls\x12\x12\n" +
+	"\x04auth\x18\t \x01(\bR\x04auth\x12\x16\n" +
+	"\x06faults\x18\n" +
+	" \x01(\bR\x06faults\"\xb6\x02\n" +
+	"\x06Limits\x12(\n" +
+	"\x10max_message_size\x18\x01 \x01(\x03R\x0emaxMessageSize\x12*\n" +
+	"\x11max_response_size\x18\x02 \x01(\x03R\x0fmaxResponseSize\x121\n" +
+	"\x15max_chunk_duration_ms\x18\x03 \x01(\x03R\x12maxChunkDurationMs\x122\n" +
+	"\x15max_concurrent_chunks\x18\x04 \x01(\x03R\x13maxConcurrentChunks\x121\n" +
+	"\x15max_queued_per_client\x18\x05 \x01(\x03R\x12maxQueuedPerClient\x12\x1d\n" +
+	"\n" +
+	"rate_limit\x18\x06 \x01(\x01R\trateLimit\x12\x1d\n" +
+	"\n" +
+	"rate_burst\x18\a \x01(\x03R\trateBurst\"\x11\n" +
+	"\x0fGetStatsRequest\"\x92\x03\n" +
+	"\vServerStats\x12\x1b\n" +
+	"\tuptime_ms\x18\x01 \x01(\x03R\buptimeMs\x12\x1b\n" +
+	"\tin_flight\x18\x02 \x01(\x03R\binFlight\x12\x16\n" +
+	"\x06chunks\x18\x03 \x01(\x03R\x06chunks\x12#\n" +
+	"\rfailed_chunks\x18\x04 \x01(\x03R\ffailedChunks\x12#\n" +
+	"\rscanned_bytes\x18\x05 \x01(\x03R\fscannedBytes\x12#\n" +
+	"\rscanned_lines\x18\x06 \x01(\x03R\fscannedLines\x12\x18\n" +
+	"\amatches\x18\a \x01(\x03R\amatches\x12\x1a\n" +
+	"\bdraining\x18\b \x01(\bR\bdraining\x12,\n" +
+	"\x12pattern_cache_size\x18\t \x01(\x03R\x10patternCacheSize\x12,\n" +
+	"\x12pattern_cache_hits\x18\n" +
+	" \x01(\x04R\x10patternCacheHits\x120\n" +
+	"\x14pattern_cache_misses\x18\v \x01(\x04R\x12patternCacheMisses\"\"\n" +
+	"\fDrainRequest\x12\x12\n" +
+	"\x04wait\x18\x01 \x01(\bR\x04wait\",\n" +
+	"\rDrainResponse\x12\x1b\n" +
+	"\tin_flight\x18\x01 \x01(\x03R\binFlight\"\xa2\x02\n" +
+	"\fMethodFaults\x12\x16\n" +
+	"\x06method\x18\x01 \x01(\tR\x06method\x12\x19\n" +
+	"\bdelay_ms\x18\x02 \x01(\x03R\adelayMs\x12+\n" +
+	"\x11delay_probability\x18\x03 \x01(\x01R\x10delayProbability\x12)\n" +
+	"\x10drop_probability\x18\x04 \x01(\x01R\x0fdropProbability\x12+\n" +
+	"\x11error_probability\x18\x05 \x01(\x01R\x10errorProbability\x12/\n" +
+	"\x13corrupt_probability\x18\x06 \x01(\x01R\x12corruptProbability\x12)\n" +
+	"\x10omit_probability\x18\a \x01(\x01R\x0fomitProbability\"T\n" +
+	"\x06Faults\x12\x18\n" +
+	"\aenabled\x18\x01 \x01(\bR\aenabled\x120\n" +
+	"\amethods\x18\x02 \x03(\v2\x16.adminsvc.MethodFaultsR\amethods\"\x12\n" +
+	"\x10GetFaultsRequest\"<\n" +
+	"\x10SetFaultsRequest\x12(\n" +
+	"\x06faults\x18\x01 \x01(\v2\x10.adminsvc.FaultsR\x06faults2\xb7\x02\n" +
+	"\fAdminService\x129\n" +
+	"\aGetInfo\x12\x18.adminsvc.GetInfoRequest\x1a\x14.adminsvc.ServerInfo\x12<\n" +
+	"\bGetStats\x12\x19.adminsvc.GetStatsRequest\x1a\x15.adminsvc.ServerStats\x128\n" +
+	"\x05Drain\x12\x16.adminsvc.DrainRequest\x1a\x17.adminsvc.DrainResponse\x129\n" +
+	"\tGetFaults\x12\x1a.adminsvc.GetFaultsRequest\x1a\x10.adminsvc.Faults\x129\n" +
+	"\tSetFaults\x12\x1a.adminsvc.SetFaultsRequest\x1a\x10.adminsvc.FaultsB\x14Z\x12/adminsvc;adminsvcb\x06proto3"
+
+var (
+	file_api_admin_service_admin_proto_rawDescOnce sync.Once
+	file_api_admin_service_admin_proto_rawDescData []byte
+)
+
+func file_api_admin_service_admin_proto_rawDescGZIP() []byte {
+	file_api_admin_service_admin_proto_rawDescOnce.Do(func() {
+		file_api_admin_service_admin_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_admin_service_admin_proto_rawDesc), len(file_api_admin_service_admin_proto_rawDesc)))
+	})
+	return file_api_admin_service_admin_proto_rawDescData
+}
+
+var file_api_admin_service_admin_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_api_admin_service_admin_proto_goTypes = []any{
+	(*GetInfoRequest)(nil),   // 0: adminsvc.GetInfoRequest
+	(*ServerInfo)(nil),       // 1: adminsvc.ServerInfo
+	(*Limits)(nil),           // 2: adminsvc.Limits
+	(*GetStatsRequest)(nil),  // 3: adminsvc.GetStatsRequest
+	(*ServerStats)(nil),      // 4: adminsvc.ServerStats
+	(*DrainRequest)(nil),     // 5: adminsvc.DrainRequest
+	(*DrainResponse)(nil),    // 6: adminsvc.DrainResponse
+	(*MethodFaults)(nil),     // 7: adminsvc.MethodFaults
+	(*Faults)(nil),           // 8: adminsvc.Faults
+	(*GetFaultsRequest)(nil), // 9: adminsvc.GetFaultsRequest
+	(*SetFaultsRequest)(nil), // 10: adminsvc.SetFaultsRequest
+}
+var file_api_admin_service_admin_proto_depIdxs = []int32{
+	2,  // 0: adminsvc.ServerInfo.limits:type_name -> adminsvc.Limits
+	7,  // 1: adminsvc.Faults.methods:type_name -> adminsvc.MethodFaults
+	8,  // 2: adminsvc.SetFaultsRequest.faults:type_name -> adminsvc.Faults
+	0,  // 3: adminsvc.AdminService.GetInfo:input_type -> adminsvc.GetInfoRequest
+	3,  // 4: adminsvc.AdminService.GetStats:input_type -> adminsvc.GetStatsRequest
+	5,  // 5: adminsvc.AdminService.Drain:input_type -> adminsvc.DrainRequest
+	9,  // 6: adminsvc.AdminService.GetFaults:input_type -> adminsvc.GetFaultsRequest
+	10, // 7: adminsvc.AdminService.SetFaults:input_type -> adminsvc.SetFaultsRequest
+	1,  // 8: adminsvc.AdminService.GetInfo:output_type -> adminsvc.ServerInfo
+	4,  // 9: adminsvc.AdminService.GetStats:output_type -> adminsvc.ServerStats
+	6,  // 10: adminsvc.AdminService.Drain:output_type -> adminsvc.DrainResponse
+	8,  // 11: adminsvc.AdminService.GetFaults:output_type -> adminsvc.Faults
+	8,  // 12: adminsvc.AdminService.SetFaults:output_type -> adminsvc.Faults
+	8,  // [8:13] is the sub-list for method output_type
+	3,  // [3:8] is the sub-list for method input_type
+	3,  // [3:3] is the sub-list for extension type_name
+	3,  // [3:3] is the sub-list for extension extendee
+	0,  // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_api_admin_service_admin_proto_init() }
+func file_api_admin_service_admin_proto_init() {
+	if File_api_admin_service_admin_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_admin_service_admin_proto_rawDesc), len(file_api_admin_service_admin_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_api_admin_service_admin_proto_goTypes,
+		DependencyIndexes: file_api_admin_service_admin_proto_depIdxs,
+		MessageInfos:      file_api_admin_service_admin_proto_msgTypes,
+	}.Build()
+	File_api_admin_service_admin_proto = out.File
+	file_api_admin_service_admin_proto_goTypes = nil
+	file_api_admin_service_admin_proto_depIdxs = nil
+}
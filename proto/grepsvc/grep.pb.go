@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.36.6
+// 	protoc-gen-go v1.36.10
 // 	protoc        v3.21.12
 // source: api/grep_service/grep.proto
 
@@ -262,7 +262,6 @@ type ChunkResponse struct {
 	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
 	Matches       []*Match               `protobuf:"bytes,2,rep,name=matches,proto3" json:"matches,omitempty"`
 	MatchCount    int64                  `protobuf:"varint,3,opt,name=match_count,json=matchCount,proto3" json:"match_count,omitempty"`
-	Error         string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -318,13 +317,6 @@ func (x *ChunkResponse) GetMatchCount() int64 {
 	return 0
 }
 
-func (x *ChunkResponse) GetError() string {
-	if x != nil {
-		return x.Error
-	}
-	return ""
-}
-
 var File_api_grep_service_grep_proto protoreflect.FileDescriptor
 
 const file_api_grep_service_grep_proto_rawDesc = "" +
@@ -351,13 +343,12 @@ const file_api_grep_service_grep_proto_rawDesc = "" +
 	"\vchunk_index\x18\x03 \x01(\x03R\n" +
 	"chunkIndex\x12!\n" +
 	"\fline_numbers\x18\x04 \x03(\x03R\vlineNumbers\x12.\n" +
-	"\aoptions\x18\x05 \x01(\v2\x14.grepsvc.GrepOptionsR\aoptions\"\x89\x01\n" +
+	"\aoptions\x18\x05 \x01(\v2\x14.grepsvc.GrepOptionsR\aoptions\"\x80\x01\n" +
 	"\rChunkResponse\x12\x17\n" +
 	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12(\n" +
 	"\amatches\x18\x02 \x03(\v2\x0e.grepsvc.MatchR\amatches\x12\x1f\n" +
 	"\vmatch_count\x18\x03 \x01(\x03R\n" +
-	"matchCount\x12\x14\n" +
-	"\x05error\x18\x04 \x01(\tR\x05error2L\n" +
+	"matchCountJ\x04\b\x04\x10\x05R\x05error2L\n" +
 	"\vGrepService\x12=\n" +
 	"\fProcessChunk\x12\x15.grepsvc.ChunkRequest\x1a\x16.grepsvc.ChunkResponseB\x12Z\x10/grepsvc;grepsvcb\x06proto3"
 